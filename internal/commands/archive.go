@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveTransport records raw feed responses to an archive directory, or replays them from it
+// instead of using the network. Entries live at <dir>/<host>/<sha256 of url>.http.
+type archiveTransport struct {
+	dir    string
+	replay bool
+	next   http.RoundTripper
+}
+
+// archivePath names the entry for a request. The whole url is hashed, so query credentials
+// select the right entry without ever being written to disk.
+func (t *archiveTransport) archivePath(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	host := strings.ReplaceAll(req.URL.Host, ":", "_")
+	return filepath.Join(t.dir, host, hex.EncodeToString(sum[:])+".http")
+}
+
+func (t *archiveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := t.archivePath(req)
+	if t.replay {
+		return t.load(req, path)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.save(resp, path); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save writes the response in HTTP wire format and leaves resp readable for the caller
+func (t *archiveTransport) save(resp *http.Response, path string) error {
+	raw, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return fmt.Errorf("failed to record response: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to record response: %w", err)
+	}
+	return nil
+}
+
+func (t *archiveTransport) load(req *http.Request, path string) (*http.Response, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no recorded response in %s", t.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded response: %w", err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recorded response %s: %w", path, err)
+	}
+	return resp, nil
+}
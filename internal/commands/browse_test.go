@@ -0,0 +1,366 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// browsePost is a listing row of feed with the given handle and title
+func browsePost(handle int64, feed, title, canonicalURL string) database.GetPostsForUserRow {
+	return database.GetPostsForUserRow{
+		ID:           uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprint("post/", handle))),
+		CreatedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(handle) * time.Hour),
+		Title:        title,
+		Url:          fmt.Sprintf("https://%s.example.com/%d", feed, handle),
+		FeedID:       uuid.NewSHA1(uuid.NameSpaceURL, []byte("feed/"+feed)),
+		CanonicalUrl: canonicalURL,
+		Handle:       handle,
+		FeedName:     feed,
+	}
+}
+
+// storyHandles lists each story as its post's handle followed by its duplicates' handles
+func storyHandles(stories []story) [][]int64 {
+	handles := [][]int64{}
+	for _, s := range stories {
+		group := []int64{s.Post.Handle}
+		for _, duplicate := range s.Duplicates {
+			group = append(group, duplicate.Handle)
+		}
+		handles = append(handles, group)
+	}
+	return handles
+}
+
+func TestGroupDuplicatePosts(t *testing.T) {
+	tests := []struct {
+		name       string
+		posts      []database.GetPostsForUserRow
+		want       [][]int64
+		wantAlsoIn [][]string
+	}{
+		{
+			name: "same canonical url in other feeds",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "Go 1.23 is out", "https://go.dev/blog/go1.23"),
+				browsePost(2, "b", "Go 1.23 released", "https://go.dev/blog/go1.23"),
+				browsePost(3, "c", "Release notes", "https://go.dev/blog/go1.23"),
+			},
+			want:       [][]int64{{1, 2, 3}},
+			wantAlsoIn: [][]string{{"b", "c"}},
+		},
+		{
+			name: "near-identical titles",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "Go 1.23 adds range over func iterators", ""),
+				browsePost(2, "b", "Go 1.23 adds range-over-func iterators!", ""),
+				browsePost(3, "b", "Something else entirely happened today", ""),
+			},
+			want:       [][]int64{{1, 2}, {3}},
+			wantAlsoIn: [][]string{{"b"}, nil},
+		},
+		{
+			name: "posts of one feed stay apart",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "Weekly news roundup for the week", "https://a.example.com/news"),
+				browsePost(2, "a", "Weekly news roundup for the week", "https://a.example.com/news"),
+			},
+			want:       [][]int64{{1}, {2}},
+			wantAlsoIn: [][]string{nil, nil},
+		},
+		{
+			name: "a story takes one post per feed",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "Go 1.23 adds range over func iterators", ""),
+				browsePost(2, "b", "Go 1.23 adds range over func iterators", ""),
+				browsePost(3, "b", "Go 1.23 adds range over func iterators", ""),
+			},
+			want:       [][]int64{{1, 2}, {3}},
+			wantAlsoIn: [][]string{{"b"}, nil},
+		},
+		{
+			name: "feeds folded by the query",
+			posts: []database.GetPostsForUserRow{
+				func() database.GetPostsForUserRow {
+					post := browsePost(1, "a", "Post", "")
+					post.AlsoIn = "b, c"
+					return post
+				}(),
+			},
+			want:       [][]int64{{1}},
+			wantAlsoIn: [][]string{{"b", "c"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stories := groupDuplicatePosts(test.posts)
+			if got := storyHandles(stories); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("groupDuplicatePosts() = %v, want %v", got, test.want)
+			}
+			for i, s := range stories {
+				if !reflect.DeepEqual(s.AlsoIn, test.wantAlsoIn[i]) {
+					t.Errorf("story %d also in %q, want %q", s.Post.Handle, s.AlsoIn, test.wantAlsoIn[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFetchBrowsePage(t *testing.T) {
+	tests := []struct {
+		name         string
+		posts        []database.GetPostsForUserRow
+		limit        int
+		backwards    bool
+		hide         string
+		want         [][]int64
+		wantBoundary int64
+		wantMore     bool
+	}{
+		{
+			name: "short fetch",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "One", ""),
+				browsePost(2, "a", "Two", ""),
+			},
+			limit:        2,
+			want:         [][]int64{{1}, {2}},
+			wantBoundary: 2,
+		},
+		{
+			name: "full fetch",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "One", ""),
+				browsePost(2, "a", "Two", ""),
+				browsePost(3, "a", "Three", ""),
+			},
+			limit:        1,
+			want:         [][]int64{{1}},
+			wantBoundary: 1,
+			wantMore:     true,
+		},
+		{
+			name: "duplicates fold into the page",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "", "https://example.com/x"),
+				browsePost(2, "b", "", "https://example.com/x"),
+				browsePost(3, "a", "Three", ""),
+				browsePost(4, "a", "Four", ""),
+			},
+			limit:        2,
+			want:         [][]int64{{1, 2}, {3}},
+			wantBoundary: 3,
+			wantMore:     true,
+		},
+		{
+			name: "a duplicate past the first dropped story",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "", "https://example.com/x"),
+				browsePost(2, "a", "Two", ""),
+				browsePost(3, "b", "", "https://example.com/x"),
+				browsePost(4, "a", "Four", ""),
+				browsePost(5, "a", "Five", ""),
+			},
+			limit:        1,
+			want:         [][]int64{{1, 3}, {2}},
+			wantBoundary: 3,
+			wantMore:     true,
+		},
+		{
+			name: "a story with a later duplicate moves to the next page",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "One", ""),
+				browsePost(2, "a", "", "https://example.com/x"),
+				browsePost(3, "a", "Three", ""),
+				browsePost(4, "b", "", "https://example.com/x"),
+				browsePost(5, "a", "Five", ""),
+			},
+			limit:        2,
+			want:         [][]int64{{1}},
+			wantBoundary: 1,
+			wantMore:     true,
+		},
+		{
+			name: "no cut keeps every story",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "", "https://example.com/x"),
+				browsePost(2, "a", "Two", ""),
+				browsePost(3, "a", "Three", ""),
+				browsePost(4, "b", "", "https://example.com/x"),
+			},
+			limit:        2,
+			want:         [][]int64{{1, 4}, {2}, {3}},
+			wantBoundary: 4,
+		},
+		{
+			name: "hidden posts are passed over",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "One", ""),
+				browsePost(2, "a", "Sponsored: Two", ""),
+				browsePost(3, "a", "Three", ""),
+				browsePost(4, "a", "Four", ""),
+			},
+			limit:        2,
+			hide:         "sponsored",
+			want:         [][]int64{{1}, {3}},
+			wantBoundary: 3,
+			wantMore:     true,
+		},
+		{
+			name: "backwards",
+			posts: []database.GetPostsForUserRow{
+				browsePost(1, "a", "One", ""),
+				browsePost(2, "a", "Two", ""),
+				browsePost(3, "a", "Three", ""),
+			},
+			limit:        2,
+			backwards:    true,
+			want:         [][]int64{{2}, {1}},
+			wantBoundary: 2,
+			wantMore:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &config.State{DB: database.New(sql.OpenDB(&fakePostsDB{posts: test.posts}))}
+			listing := postListing{Backwards: test.backwards}
+			rules := &userRules{}
+			if test.hide != "" {
+				pattern, err := compileRule(ruleKeyword, test.hide)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rules.rules = append(rules.rules, compiledRule{
+					rule:    database.GetRulesForUserRow{Kind: ruleKeyword, Action: actionHide},
+					pattern: pattern,
+				})
+			}
+			stories, boundary, more, err := fetchBrowsePage(context.Background(), state, listing, test.limit, false, rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := storyHandles(stories); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("stories = %v, want %v", got, test.want)
+			}
+			if boundary.Handle != test.wantBoundary || more != test.wantMore {
+				t.Errorf("boundary %d, more %v, want %d, %v", boundary.Handle, more, test.wantBoundary, test.wantMore)
+			}
+		})
+	}
+}
+
+func TestBrowsePagesListEveryPostOnce(t *testing.T) {
+	posts := []database.GetPostsForUserRow{
+		browsePost(1, "a", "", "https://example.com/x"),
+		browsePost(2, "a", "Two", ""),
+		browsePost(3, "b", "", "https://example.com/x"),
+		browsePost(4, "a", "Go 1.23 adds range over func iterators", ""),
+		browsePost(5, "c", "", "https://example.com/y"),
+		browsePost(6, "b", "Go 1.23 adds range over func iterators", ""),
+		browsePost(7, "a", "", "https://example.com/y"),
+		browsePost(8, "a", "Eight", ""),
+		browsePost(9, "b", "Nine", ""),
+	}
+	for limit := 1; limit <= 3; limit++ {
+		t.Run(fmt.Sprint("limit ", limit), func(t *testing.T) {
+			state := &config.State{DB: database.New(sql.OpenDB(&fakePostsDB{posts: posts}))}
+			var listing postListing
+			var seen []int64
+			for page := 0; page < len(posts); page++ {
+				stories, boundary, more, err := fetchBrowsePage(context.Background(), state, listing, limit, false, &userRules{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, group := range storyHandles(stories) {
+					seen = append(seen, group...)
+				}
+				if !more {
+					break
+				}
+				nextBrowsePage(&listing, boundary)
+			}
+			slices.Sort(seen)
+			if want := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(seen, want) {
+				t.Errorf("pages listed %v, want %v", seen, want)
+			}
+		})
+	}
+}
+
+// fakePostsDB serves posts, in the order given, to the GetPostsForUser queries: each query lists
+// the posts after the one its cursor id names, up to the row limit that ends its arguments
+type fakePostsDB struct {
+	posts []database.GetPostsForUserRow
+}
+
+func (db *fakePostsDB) Connect(context.Context) (driver.Conn, error) { return fakePostsConn{db}, nil }
+func (db *fakePostsDB) Driver() driver.Driver                        { return nil }
+
+type fakePostsConn struct {
+	db *fakePostsDB
+}
+
+func (c fakePostsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakePostsConn) Close() error                        { return nil }
+func (c fakePostsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakePostsConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	start := 0
+	for _, arg := range args {
+		for i, post := range c.db.posts {
+			if arg.Value == post.ID.String() {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+int(args[len(args)-1].Value.(int64)), len(c.db.posts))
+	return &fakePostRows{posts: c.db.posts[start:end]}, nil
+}
+
+type fakePostRows struct {
+	posts []database.GetPostsForUserRow
+}
+
+func (r *fakePostRows) Columns() []string {
+	var columns []string
+	for _, field := range reflect.VisibleFields(reflect.TypeFor[database.GetPostsForUserRow]()) {
+		columns = append(columns, field.Name)
+	}
+	return columns
+}
+
+func (r *fakePostRows) Close() error { return nil }
+
+func (r *fakePostRows) Next(dest []driver.Value) error {
+	if len(r.posts) == 0 {
+		return io.EOF
+	}
+	row := reflect.ValueOf(r.posts[0])
+	r.posts = r.posts[1:]
+	for i := range dest {
+		value := row.Field(i).Interface()
+		if list, ok := value.([]string); ok {
+			value = pq.Array(list)
+		}
+		if valuer, ok := value.(driver.Valuer); ok {
+			var err error
+			if value, err = valuer.Value(); err != nil {
+				return err
+			}
+		}
+		dest[i] = value
+	}
+	return nil
+}
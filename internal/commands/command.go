@@ -1,8 +1,11 @@
 package commands
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"github.com/maevlava/Gator/internal/config"
+	"io"
 )
 
 type CLI struct {
@@ -10,14 +13,16 @@ type CLI struct {
 	Args []string
 }
 type Registry struct {
-	Commands map[string]func(state *config.State, command CLI) error
+	Commands map[string]func(ctx context.Context, state *config.State, command CLI) error
 }
 
-func (c *Registry) Register(name string, f func(*config.State, CLI) error) {
+func (c *Registry) Register(name string, f func(context.Context, *config.State, CLI) error) {
 	// register command
 	c.Commands[name] = f
 }
-func (c *Registry) Run(s *config.State, cmd CLI) error {
+
+// Run executes cmd; ctx is cancelled when the process is asked to stop
+func (c *Registry) Run(ctx context.Context, s *config.State, cmd CLI) error {
 	// find command
 	command, ok := c.Commands[cmd.Name]
 
@@ -26,10 +31,28 @@ func (c *Registry) Run(s *config.State, cmd CLI) error {
 	}
 
 	// if exist, execute it with current state e.g. user
-	err := command(s, cmd)
+	err := command(ctx, s, cmd)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// parseFlags parses flags that may appear anywhere among the command's arguments
+// and returns the remaining positional arguments in order.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
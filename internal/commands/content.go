@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxContentBytes bounds the HTML stored for a single post
+const maxContentBytes = 256 << 10
+
+var (
+	// elements whose content is never worth keeping in a terminal reader
+	unsafeElementPattern = regexp.MustCompile(`(?is)<(script|style|iframe|object|embed|form|noscript)\b.*?</(script|style|iframe|object|embed|form|noscript)\s*>|<(script|style|iframe|object|embed|form|noscript|link|meta|base)\b[^>]*>`)
+	eventAttrPattern     = regexp.MustCompile(`(?i)\s+on[a-z]+\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	scriptURLPattern     = regexp.MustCompile(`(?i)(href|src)\s*=\s*("\s*javascript:[^"]*"|'\s*javascript:[^']*'|javascript:[^\s>]+)`)
+	blockTagPattern      = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6]|/blockquote|/pre|/tr)\b[^>]*>`)
+	blankLinesPattern    = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// cleanContent sanitizes post HTML and truncates it to maxContentBytes
+func cleanContent(content string) string {
+	content = unsafeElementPattern.ReplaceAllString(content, "")
+	content = eventAttrPattern.ReplaceAllString(content, "")
+	content = scriptURLPattern.ReplaceAllString(content, `$1="#"`)
+	content = strings.TrimSpace(content)
+
+	if len(content) > maxContentBytes {
+		cut := maxContentBytes
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		content = content[:cut]
+	}
+	return content
+}
+
+// htmlToText renders post HTML as plain text, one block per line
+func htmlToText(content string) string {
+	content = blockTagPattern.ReplaceAllString(content, "\n")
+	content = html.UnescapeString(tagPattern.ReplaceAllString(content, ""))
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n"))
+}
+
+// contentHash fingerprints the parts of a post a publisher may correct
+func contentHash(title, description, content string) string {
+	sum := sha256.New()
+	for _, part := range []string{title, description, content} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// wrapText breaks text into lines of at most width runes, at spaces where possible
+func wrapText(text string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,33 @@
+package commands
+
+import "testing"
+
+func TestContentHash(t *testing.T) {
+	type post struct{ title, description, content string }
+	base := post{"Title", "Summary", "<p>Body</p>"}
+	tests := []struct {
+		name string
+		a, b post
+		same bool
+	}{
+		{name: "identical", a: base, b: base, same: true},
+		{name: "title corrected", a: base, b: post{"Title, fixed", "Summary", "<p>Body</p>"}},
+		{name: "description changed", a: base, b: post{"Title", "Summary.", "<p>Body</p>"}},
+		{name: "content changed", a: base, b: post{"Title", "Summary", "<p>Body!</p>"}},
+		// the separator keeps text moving between fields from hashing the same
+		{name: "text moved between fields", a: post{"ab", "c", ""}, b: post{"a", "bc", ""}},
+		{name: "description moved into content", a: post{"T", "x", ""}, b: post{"T", "", "x"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := contentHash(test.a.title, test.a.description, test.a.content)
+			b := contentHash(test.b.title, test.b.description, test.b.content)
+			if len(a) != 64 {
+				t.Fatalf("contentHash() = %q, want 64 hex characters", a)
+			}
+			if (a == b) != test.same {
+				t.Errorf("contentHash(%+v) == contentHash(%+v) is %v, want %v", test.a, test.b, a == b, test.same)
+			}
+		})
+	}
+}
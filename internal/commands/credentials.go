@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/maevlava/Gator/internal/database"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Supported values of feeds.auth_type
+const (
+	authBasic  = "basic"
+	authBearer = "bearer"
+	authQuery  = "query"
+)
+
+var errNoCredentialsKey = errors.New("no credentials key: set credentials_key in the config file or GATOR_CREDENTIALS_KEY")
+
+// newCredentialsCipher derives an AES-256-GCM cipher from the configured key
+func newCredentialsCipher(key string) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, errNoCredentialsKey
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptSecret returns nonce || ciphertext for storage in feeds.auth_secret
+func encryptSecret(key, secret string) ([]byte, error) {
+	aead, err := newCredentialsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, []byte(secret), nil), nil
+}
+
+// decryptSecret reverses encryptSecret
+func decryptSecret(key string, sealed []byte) (string, error) {
+	aead, err := newCredentialsCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("stored secret is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt stored secret, was the credentials key changed?")
+	}
+	return string(plaintext), nil
+}
+
+// readSecret resolves the --secret flag, reading the first line of stdin for "-"
+// so secrets do not have to appear in shell history.
+func readSecret(secret string) (string, error) {
+	if secret != "-" {
+		return secret, nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// validateCredentials checks that the addfeed auth flags form a usable combination
+func validateCredentials(authType, param, secret string) error {
+	switch authType {
+	case "":
+		if param != "" || secret != "" {
+			return errors.New("--auth-param and --secret require --auth")
+		}
+	case authBasic:
+		if param == "" {
+			return errors.New("basic auth requires --auth-param with the username")
+		}
+	case authBearer:
+		if param != "" {
+			return errors.New("bearer auth does not take --auth-param")
+		}
+	case authQuery:
+		if param == "" {
+			return errors.New("query auth requires --auth-param with the parameter name")
+		}
+	default:
+		return fmt.Errorf("unknown auth type '%s': expected basic, bearer or query", authType)
+	}
+	if authType != "" && secret == "" {
+		return fmt.Errorf("%s auth requires --secret", authType)
+	}
+	return nil
+}
+
+// applyCredentials adds the feed's decrypted credentials to an outgoing request
+func applyCredentials(req *http.Request, feed database.Feed, key string) error {
+	if !feed.AuthType.Valid {
+		return nil
+	}
+	secret, err := decryptSecret(key, feed.AuthSecret)
+	if err != nil {
+		return err
+	}
+
+	switch feed.AuthType.String {
+	case authBasic:
+		req.SetBasicAuth(feed.AuthParam.String, secret)
+	case authBearer:
+		req.Header.Set("Authorization", "Bearer "+secret)
+	case authQuery:
+		query := req.URL.Query()
+		query.Set(feed.AuthParam.String, secret)
+		req.URL.RawQuery = query.Encode()
+	default:
+		return fmt.Errorf("unknown auth type '%s'", feed.AuthType.String)
+	}
+	return nil
+}
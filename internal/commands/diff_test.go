@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "unchanged", a: "a b c", b: "a b c", want: " a  b  c"},
+		{name: "both empty", a: "", b: "", want: ""},
+		{name: "all added", a: "", b: "a b", want: "+a +b"},
+		{name: "all removed", a: "a b", b: "", want: "-a -b"},
+		{name: "line replaced", a: "a b c", b: "a x c", want: " a -b +x  c"},
+		{name: "line inserted", a: "a c", b: "a b c", want: " a +b  c"},
+		{name: "line removed at end", a: "a b c", b: "a b", want: " a  b -c"},
+		{name: "moved line", a: "a b c", b: "b c a", want: "-a  b  c +a"},
+		{name: "duplicates", a: "x x y", b: "x y y", want: " x -x  y +y"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ops []string
+			for _, op := range diffLines(strings.Fields(test.a), strings.Fields(test.b)) {
+				ops = append(ops, string(op.Kind)+op.Line)
+			}
+			if got := strings.Join(ops, " "); got != test.want {
+				t.Errorf("diffLines(%q, %q) = %q, want %q", test.a, test.b, got, test.want)
+			}
+
+			// applying the diff must give back both sides
+			var before, after []string
+			for _, op := range diffLines(strings.Fields(test.a), strings.Fields(test.b)) {
+				if op.Kind != '+' {
+					before = append(before, op.Line)
+				}
+				if op.Kind != '-' {
+					after = append(after, op.Line)
+				}
+			}
+			if !reflect.DeepEqual(before, strings.Fields(test.a)) && len(before)+len(strings.Fields(test.a)) > 0 {
+				t.Errorf("old side = %v, want %v", before, strings.Fields(test.a))
+			}
+			if !reflect.DeepEqual(after, strings.Fields(test.b)) && len(after)+len(strings.Fields(test.b)) > 0 {
+				t.Errorf("new side = %v, want %v", after, strings.Fields(test.b))
+			}
+		})
+	}
+}
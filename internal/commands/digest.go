@@ -0,0 +1,334 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// digestExcerptChars bounds the excerpt shown for each post
+const digestExcerptChars = 280
+
+// digestFormats are the formats renderDigest writes
+var digestFormats = []string{"md", "html", "txt"}
+
+// uncategorized is the group for posts without a category
+const uncategorized = "Uncategorized"
+
+// digest is a user's unread posts over a period, grouped for reading in one go
+type digest struct {
+	User   string
+	Since  time.Time
+	Total  int
+	Groups []digestGroup
+	// stories are the posts included, to mark read once the digest is delivered
+	stories []story
+}
+
+type digestGroup struct {
+	Name  string
+	Posts []digestPost
+}
+
+type digestPost struct {
+	Handle    int64
+	Title     string
+	URL       string
+	Feed      string
+	Date      time.Time
+	Excerpt   string
+	Highlight bool
+}
+
+// DigestHandler writes a summary of the current user's unread posts:
+// digest [--since 24h] [--format md|html|txt] [--by feed|category] [--max N] [--out file] [--mark-read].
+// digest schedule and digest send manage and mail scheduled digests.
+func DigestHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if len(cmd.Args) > 0 {
+		switch cmd.Args[0] {
+		case "schedule":
+			return digestScheduleCommand(ctx, state, cmd.Args[1:], user)
+		case "send":
+			return digestSendCommand(ctx, state, cmd.Args[1:], user)
+		}
+	}
+
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	sinceFlag := fs.String("since", "24h", "include posts from this date, or this long ago like 24h or 7d")
+	format := fs.String("format", "md", "md, html or txt")
+	by := fs.String("by", "feed", "group posts by feed or category")
+	maxPosts := fs.Int("max", 200, "most posts to include, newest first")
+	out := fs.String("out", "", "write the digest to this file instead of stdout")
+	markRead := fs.Bool("mark-read", false, "mark the included posts read")
+	if _, err := parseFlags(fs, cmd.Args); err != nil {
+		return fmt.Errorf("invalid digest flags: %w", err)
+	}
+	if *maxPosts <= 0 {
+		return errors.New("max must be a positive integer")
+	}
+	if !slices.Contains(digestFormats, *format) {
+		return fmt.Errorf("invalid format '%s': must be md, html or txt", *format)
+	}
+	since, err := parseTimeBound(*sinceFlag, false)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+
+	d, err := buildDigest(ctx, state, user, digestWindow{Since: since}, *by, *maxPosts)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := renderDigest(w, d, *format); err != nil {
+		return err
+	}
+	if *out != "" {
+		fmt.Printf("Wrote digest of %d posts to %s\n", d.Total, *out)
+	}
+
+	if *markRead {
+		return markDigestRead(ctx, state, user, d)
+	}
+	return nil
+}
+
+// digestWindow is the span of posts a digest covers
+type digestWindow struct {
+	Since time.Time
+	// Until is the end of the window, open when zero
+	Until time.Time
+	// FirstSeen windows on when gator stored each post rather than its publication date, so that
+	// back-to-back windows cover every post exactly once
+	FirstSeen bool
+}
+
+// buildDigest gathers up to maxPosts of user's unread posts in window, after rules, grouped by feed or
+// by category. A post with several categories is listed under its first.
+func buildDigest(ctx context.Context, state *config.State, user database.User, window digestWindow, by string, maxPosts int) (digest, error) {
+	d := digest{User: user.Name, Since: window.Since}
+	switch by {
+	case "feed", "category":
+	default:
+		return d, fmt.Errorf("invalid --by '%s': must be feed or category", by)
+	}
+
+	rules, err := loadRules(ctx, state, user)
+	if err != nil {
+		return d, err
+	}
+	listing := postListing{
+		SortBy: "published",
+		GetPostsForUserParams: database.GetPostsForUserParams{
+			UserID:     user.ID,
+			UnreadOnly: true,
+			Since:      window.Since,
+			Until:      window.Until,
+		},
+	}
+	if window.FirstSeen {
+		listing.SortBy = "fetched"
+	}
+	for len(d.stories) < maxPosts {
+		stories, boundary, more, err := browsePage(ctx, state, listing, maxPosts-len(d.stories), false, rules)
+		if err != nil {
+			return d, fmt.Errorf("failed to get posts for user '%s': %w", user.Name, err)
+		}
+		d.stories = append(d.stories, stories...)
+		if !more {
+			break
+		}
+		nextBrowsePage(&listing, boundary)
+	}
+
+	groups := make(map[string]*digestGroup)
+	for _, story := range d.stories {
+		post := story.Post
+		name := post.FeedName
+		if by == "category" {
+			name = uncategorized
+			if len(post.Categories) > 0 && strings.TrimSpace(post.Categories[0]) != "" {
+				name = strings.TrimSpace(post.Categories[0])
+			}
+		}
+		group, ok := groups[name]
+		if !ok {
+			group = &digestGroup{Name: name}
+			groups[name] = group
+		}
+
+		date := post.CreatedAt
+		if post.PublishedAt.Valid {
+			date = post.PublishedAt.Time
+		}
+		// the extracted article reads better than a feed summary that is often just a teaser
+		excerpt := htmlToText(post.Article.String)
+		if excerpt == "" {
+			excerpt = htmlToText(post.Description.String)
+		}
+		if excerpt == "" {
+			excerpt = htmlToText(post.Content.String)
+		}
+		group.Posts = append(group.Posts, digestPost{
+			Handle:    post.Handle,
+			Title:     post.Title,
+			URL:       post.Url,
+			Feed:      post.FeedName,
+			Date:      date,
+			Excerpt:   truncate(excerpt, digestExcerptChars),
+			Highlight: story.Highlight,
+		})
+	}
+
+	for _, group := range groups {
+		d.Groups = append(d.Groups, *group)
+	}
+	slices.SortFunc(d.Groups, func(a, b digestGroup) int {
+		// posts without a category come last
+		if (a.Name == uncategorized) != (b.Name == uncategorized) {
+			if a.Name == uncategorized {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	d.Total = len(d.stories)
+	return d, nil
+}
+
+// markDigestRead marks every post a digest included as read, with the duplicates grouped under them
+func markDigestRead(ctx context.Context, state *config.State, user database.User, d digest) error {
+	now := time.Now().UTC()
+	for _, story := range d.stories {
+		// duplicates folded into the story were shown with it, so they are read too
+		for _, post := range append([]database.GetPostsForUserRow{story.Post}, story.Duplicates...) {
+			_, err := state.DB.MarkPostRead(ctx, database.MarkPostReadParams{
+				UserID: user.ID,
+				ReadAt: now,
+				PostID: post.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to mark post '%s' read: %w", post.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+// renderDigest writes d as Markdown, HTML or plain text
+func renderDigest(w io.Writer, d digest, format string) error {
+	switch format {
+	case "md":
+		return renderDigestMarkdown(w, d)
+	case "html":
+		return digestHTML.Execute(w, d)
+	case "txt":
+		return renderDigestText(w, d)
+	}
+	return fmt.Errorf("invalid format '%s': must be md, html or txt", format)
+}
+
+// digestSummary is the line under a digest's heading
+func digestSummary(d digest) string {
+	if d.Total == 0 {
+		return fmt.Sprintf("No unread posts since %s", d.Since.Format(time.RFC1123))
+	}
+	if d.Total == 1 {
+		return fmt.Sprintf("1 unread post since %s", d.Since.Format(time.RFC1123))
+	}
+	return fmt.Sprintf("%d unread posts since %s", d.Total, d.Since.Format(time.RFC1123))
+}
+
+func renderDigestText(w io.Writer, d digest) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Digest for %s\n%s\n", d.User, digestSummary(d))
+	for _, group := range d.Groups {
+		fmt.Fprintf(&b, "\n== %s (%d) ==\n", group.Name, len(group.Posts))
+		for _, post := range group.Posts {
+			fmt.Fprintf(&b, "\n[%d] %s%s\n", post.Handle, post.Title, highlightMark(post.Highlight))
+			fmt.Fprintf(&b, "    %s, %s\n", post.Feed, post.Date.Format(time.RFC1123))
+			for _, line := range wrapText(post.Excerpt, 72) {
+				if line != "" {
+					fmt.Fprintf(&b, "    %s\n", line)
+				}
+			}
+			fmt.Fprintf(&b, "    %s\n", post.URL)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscaper escapes the characters that would turn a title or excerpt into markup
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+// markdownURLEscaper keeps a url inside <...> link destinations
+var markdownURLEscaper = strings.NewReplacer("<", "%3C", ">", "%3E")
+
+func renderDigestMarkdown(w io.Writer, d digest) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Digest for %s\n\n%s\n", markdownEscaper.Replace(d.User), digestSummary(d))
+	for _, group := range d.Groups {
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", markdownEscaper.Replace(group.Name), len(group.Posts))
+		for _, post := range group.Posts {
+			title := fmt.Sprintf("[%s](<%s>)", markdownEscaper.Replace(post.Title), markdownURLEscaper.Replace(post.URL))
+			if post.Highlight {
+				title = "**" + title + "**"
+			}
+			fmt.Fprintf(&b, "- %s  \n  %s, %s\n", title, markdownEscaper.Replace(post.Feed), post.Date.Format(time.RFC1123))
+			if post.Excerpt != "" {
+				fmt.Fprintf(&b, "\n  %s\n", markdownEscaper.Replace(post.Excerpt))
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var digestHTML = htmltemplate.Must(htmltemplate.New("digest").Funcs(htmltemplate.FuncMap{
+	"summary": digestSummary,
+	"date":    func(t time.Time) string { return t.Format(time.RFC1123) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Digest for {{.User}}</title>
+</head>
+<body style="font-family: sans-serif; max-width: 40em; margin: auto; line-height: 1.4">
+<h1>Digest for {{.User}}</h1>
+<p>{{summary .}}</p>
+{{range .Groups}}
+<h2>{{.Name}} ({{len .Posts}})</h2>
+{{range .Posts}}
+<div style="margin-bottom: 1.2em">
+<a href="{{.URL}}">{{if .Highlight}}<strong>{{.Title}}</strong>{{else}}{{.Title}}{{end}}</a><br>
+<small style="color: #666">{{.Feed}}, {{date .Date}}</small>
+{{if .Excerpt}}<p style="margin: 0.3em 0">{{.Excerpt}}</p>{{end}}
+</div>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,481 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"html"
+	"io"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// minArticleChars is the least text a candidate needs to count as the article
+const minArticleChars = 250
+
+var (
+	// class and id hints used to weigh candidate containers
+	positiveHintPattern = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|story|text`)
+	negativeHintPattern = regexp.MustCompile(`(?i)banner|breadcrumb|comment|cookie|footer|footnote|masthead|menu|meta|modal|nav|newsletter|outbrain|popup|promo|related|share|sidebar|social|sponsor|subscribe|tags|widget|\bads?\b|advert`)
+	htmlCommentPattern  = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// skippedArticleTags never hold article text
+var skippedArticleTags = map[string]bool{
+	"aside": true, "button": true, "footer": true, "form": true, "header": true,
+	"input": true, "nav": true, "select": true, "svg": true, "textarea": true,
+}
+
+// keptArticleTags survive in the extracted article; other elements are unwrapped
+var keptArticleTags = map[string]bool{
+	"a": true, "b": true, "blockquote": true, "br": true, "code": true, "dd": true, "dl": true, "dt": true,
+	"em": true, "figcaption": true, "figure": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "hr": true, "i": true, "img": true, "li": true, "ol": true, "p": true, "pre": true,
+	"strong": true, "sub": true, "sup": true, "table": true, "tbody": true, "td": true, "th": true,
+	"thead": true, "tr": true, "ul": true,
+}
+
+// articleExcerptChars bounds the extracted article text shown in listings
+const articleExcerptChars = 400
+
+// articleExcerpt is the start of an extracted article as a single line of text, empty when there is none
+func articleExcerpt(article sql.NullString) string {
+	return truncate(htmlToText(article.String), articleExcerptChars)
+}
+
+// htmlNode is an element, or a text node when Tag is empty
+type htmlNode struct {
+	Tag      string
+	Attrs    map[string]string
+	Text     string
+	Parent   *htmlNode
+	Children []*htmlNode
+}
+
+// fetchArticle downloads a post's page and returns its sanitized main content.
+// The feed's TLS settings apply, but its credentials are only sent to the feed's own host.
+func fetchArticle(ctx context.Context, cfg *config.Config, feed database.Feed, pageURL string) (string, error) {
+	page := feed
+	page.Url = pageURL
+	if !sameHost(feed.Url, pageURL) {
+		page.AuthType = nullString("")
+		page.AuthParam = nullString("")
+		page.AuthSecret = nil
+	}
+
+	resp, err := fetchFeedBody(ctx, cfg, page)
+	if err != nil {
+		return "", err
+	}
+
+	article, err := extractArticle(resp.Body, pageURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract article from %s: %w", pageURL, err)
+	}
+	return cleanContent(article), nil
+}
+
+func sameHost(a, b string) bool {
+	ua, err := neturl.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := neturl.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Host, ub.Host)
+}
+
+// extractArticle finds the element holding most of a page's prose and renders it as simple HTML,
+// scoring paragraphs into their ancestors the way readability-style extractors do.
+func extractArticle(body []byte, pageURL string) (string, error) {
+	root := parseHTML(body)
+
+	scores := make(map[*htmlNode]float64)
+	var candidates []*htmlNode
+	walkHTML(root, func(node *htmlNode) {
+		switch node.Tag {
+		case "p", "pre", "td", "blockquote":
+		default:
+			return
+		}
+		text := nodeText(node)
+		if len(text) < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+
+		for level, ancestor := 0, node.Parent; level < 2 && ancestor != nil && ancestor.Tag != ""; level, ancestor = level+1, ancestor.Parent {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+				candidates = append(candidates, ancestor)
+			}
+			if level == 0 {
+				scores[ancestor] += score
+			} else {
+				scores[ancestor] += score / 2
+			}
+		}
+	})
+
+	var best *htmlNode
+	bestScore := 0.0
+	for _, candidate := range candidates {
+		score := scores[candidate] * (1 - linkDensity(candidate))
+		if best == nil || score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if best == nil || len(nodeText(best)) < minArticleChars {
+		return "", errors.New("no article content found")
+	}
+
+	base, _ := neturl.Parse(pageURL)
+	var out strings.Builder
+	for _, child := range best.Children {
+		renderArticle(&out, child, base)
+	}
+	return out.String(), nil
+}
+
+// parseHTML builds a forgiving element tree. Real pages are rarely well-formed, so nothing here
+// fails: stray end tags are ignored, unclosed elements close at the end, and a '<' that doesn't
+// start a tag is text. Scripts, styles and comments are dropped up front.
+func parseHTML(body []byte) *htmlNode {
+	body = decodeHTMLCharset(body)
+	body = htmlCommentPattern.ReplaceAll(body, nil)
+	body = unsafeElementPattern.ReplaceAll(body, nil)
+	page := string(body)
+
+	root := &htmlNode{Tag: "#document"}
+	current := root
+	for len(page) > 0 {
+		if !startsHTMLTag(page) {
+			end := 1 + indexHTMLTag(page[1:])
+			current.Children = append(current.Children, &htmlNode{Text: html.UnescapeString(page[:end]), Parent: current})
+			page = page[end:]
+			continue
+		}
+
+		end := indexTagEnd(page)
+		if end < 0 {
+			// a tag cut off by the end of the page is just text
+			current.Children = append(current.Children, &htmlNode{Text: html.UnescapeString(page), Parent: current})
+			break
+		}
+		tag := page[:end+1]
+		page = page[end+1:]
+
+		switch {
+		case tag[1] == '!' || tag[1] == '?':
+			// doctype and processing instructions
+		case tag[1] == '/':
+			// close up to the matching element, ignoring stray end tags
+			name, _ := parseHTMLTag(tag[2:])
+			for open := current; open != root; open = open.Parent {
+				if open.Tag == name {
+					current = open.Parent
+					break
+				}
+			}
+		default:
+			name, attrs := parseHTMLTag(tag[1:])
+			for current != root && impliedEndTags[current.Tag][name] {
+				current = current.Parent
+			}
+			node := &htmlNode{Tag: name, Attrs: attrs, Parent: current}
+			current.Children = append(current.Children, node)
+			if !voidHTMLTags[name] && !strings.HasSuffix(tag, "/>") {
+				current = node
+			}
+		}
+	}
+	return root
+}
+
+// voidHTMLTags never have content or an end tag
+var voidHTMLTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// impliedEndTags lists, for an open element, the start tags that close it without an end tag
+var impliedEndTags = func() map[string]map[string]bool {
+	blocks := map[string]bool{}
+	for _, tag := range strings.Fields("address article aside blockquote div dl fieldset figure footer form h1 h2 h3 h4 h5 h6 header hr main nav ol p pre section table ul") {
+		blocks[tag] = true
+	}
+	return map[string]map[string]bool{
+		"p":  blocks,
+		"li": {"li": true},
+		"dt": {"dt": true, "dd": true},
+		"dd": {"dt": true, "dd": true},
+		"td": {"td": true, "th": true, "tr": true},
+		"th": {"td": true, "th": true, "tr": true},
+		"tr": {"tr": true},
+	}
+}()
+
+// startsHTMLTag reports whether page begins with a tag rather than a literal '<'
+func startsHTMLTag(page string) bool {
+	if len(page) < 2 || page[0] != '<' {
+		return false
+	}
+	c := page[1]
+	if c == '/' && len(page) > 2 {
+		c = page[2]
+	} else if c == '!' || c == '?' {
+		return true
+	}
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// indexHTMLTag is the offset of the next tag in page, or len(page) when there is none
+func indexHTMLTag(page string) int {
+	for offset := 0; ; {
+		i := strings.IndexByte(page[offset:], '<')
+		if i < 0 {
+			return len(page)
+		}
+		if startsHTMLTag(page[offset+i:]) {
+			return offset + i
+		}
+		offset += i + 1
+	}
+}
+
+// indexTagEnd finds the '>' closing the tag at the start of page, skipping quoted attribute values
+func indexTagEnd(page string) int {
+	var quote byte
+	afterEquals := false
+	for i := 1; i < len(page); i++ {
+		c := page[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '>':
+			return i
+		case afterEquals && (c == '"' || c == '\''):
+			// only a quote opening a value hides '>'; elsewhere it is a stray character
+			quote = c
+		}
+		if c == '=' {
+			afterEquals = true
+		} else if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			afterEquals = false
+		}
+	}
+	return -1
+}
+
+// parseHTMLTag reads the lowercased name and attributes of a tag with its '<' or '</' removed
+func parseHTMLTag(tag string) (string, map[string]string) {
+	tag = strings.TrimSuffix(strings.TrimSuffix(tag, ">"), "/")
+	nameEnd := strings.IndexAny(tag, " \t\r\n/")
+	if nameEnd < 0 {
+		nameEnd = len(tag)
+	}
+	name := strings.ToLower(tag[:nameEnd])
+	attrs := make(map[string]string)
+	rest := tag[nameEnd:]
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n/")
+		if rest == "" {
+			return name, attrs
+		}
+		keyEnd := strings.IndexAny(rest, " \t\r\n/=")
+		if keyEnd < 0 {
+			keyEnd = len(rest)
+		}
+		key := strings.ToLower(rest[:keyEnd])
+		rest = strings.TrimLeft(rest[keyEnd:], " \t\r\n")
+		value := key
+		if strings.HasPrefix(rest, "=") {
+			rest = strings.TrimLeft(rest[1:], " \t\r\n")
+			value, rest = splitAttrValue(rest)
+		}
+		if _, seen := attrs[key]; !seen && key != "" {
+			attrs[key] = html.UnescapeString(value)
+		}
+	}
+}
+
+// splitAttrValue cuts a quoted or bare attribute value off the front of s
+func splitAttrValue(s string) (string, string) {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		if end := strings.IndexByte(s[1:], s[0]); end >= 0 {
+			return s[1 : end+1], s[end+2:]
+		}
+		return s[1:], ""
+	}
+	end := strings.IndexAny(s, " \t\r\n")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+var metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?([\w-]+)`)
+
+// decodeHTMLCharset converts a page that isn't UTF-8 using its meta charset,
+// replacing whatever still doesn't decode
+func decodeHTMLCharset(body []byte) []byte {
+	if utf8.Valid(body) {
+		return body
+	}
+	if m := metaCharsetPattern.FindSubmatch(body); m != nil {
+		if reader, err := charsetReader(string(m[1]), bytes.NewReader(body)); err == nil {
+			if decoded, err := io.ReadAll(reader); err == nil {
+				body = decoded
+			}
+		}
+	}
+	return bytes.ToValidUTF8(body, []byte("\uFFFD"))
+}
+
+// walkHTML visits element nodes depth-first, skipping boilerplate subtrees
+func walkHTML(node *htmlNode, visit func(*htmlNode)) {
+	for _, child := range node.Children {
+		if child.Tag == "" || isBoilerplate(child) {
+			continue
+		}
+		visit(child)
+		walkHTML(child, visit)
+	}
+}
+
+func isBoilerplate(node *htmlNode) bool {
+	if skippedArticleTags[node.Tag] {
+		return true
+	}
+	hints := node.Attrs["class"] + " " + node.Attrs["id"]
+	return negativeHintPattern.MatchString(hints) && !positiveHintPattern.MatchString(hints)
+}
+
+func initialScore(node *htmlNode) float64 {
+	var score float64
+	switch node.Tag {
+	case "article", "main":
+		score = 10
+	case "div":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "ol", "ul", "dl", "dd", "dt", "li":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+
+	hints := node.Attrs["class"] + " " + node.Attrs["id"]
+	if positiveHintPattern.MatchString(hints) {
+		score += 25
+	}
+	if negativeHintPattern.MatchString(hints) {
+		score -= 25
+	}
+	return score
+}
+
+// nodeText is the whitespace-collapsed text under node, boilerplate excluded
+func nodeText(node *htmlNode) string {
+	var text strings.Builder
+	var collect func(*htmlNode)
+	collect = func(n *htmlNode) {
+		for _, child := range n.Children {
+			if child.Tag == "" {
+				text.WriteString(child.Text)
+				text.WriteByte(' ')
+			} else if !isBoilerplate(child) {
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+	return strings.Join(strings.Fields(text.String()), " ")
+}
+
+// linkDensity is the share of node's text that sits inside links
+func linkDensity(node *htmlNode) float64 {
+	total := len(nodeText(node))
+	if total == 0 {
+		return 1
+	}
+	linked := 0
+	walkHTML(node, func(n *htmlNode) {
+		if n.Tag == "a" {
+			linked += len(nodeText(n))
+		}
+	})
+	return min(float64(linked)/float64(total), 1)
+}
+
+// renderArticle writes node as HTML limited to keptArticleTags, resolving links against base
+func renderArticle(out *strings.Builder, node *htmlNode, base *neturl.URL) {
+	if node.Tag == "" {
+		out.WriteString(html.EscapeString(node.Text))
+		return
+	}
+	if isBoilerplate(node) {
+		return
+	}
+	if !keptArticleTags[node.Tag] {
+		for _, child := range node.Children {
+			renderArticle(out, child, base)
+		}
+		return
+	}
+
+	switch node.Tag {
+	case "a":
+		out.WriteString("<a")
+		if href := resolveArticleURL(base, node.Attrs["href"]); href != "" {
+			fmt.Fprintf(out, ` href="%s"`, html.EscapeString(href))
+		}
+		out.WriteString(">")
+	case "img":
+		// lazy-loaded images keep the real source in data-src
+		src := resolveArticleURL(base, node.Attrs["src"])
+		if data := resolveArticleURL(base, node.Attrs["data-src"]); data != "" {
+			src = data
+		}
+		if src != "" {
+			fmt.Fprintf(out, `<img src="%s" alt="%s">`, html.EscapeString(src), html.EscapeString(node.Attrs["alt"]))
+		}
+	default:
+		out.WriteString("<" + node.Tag + ">")
+	}
+
+	switch node.Tag {
+	case "br", "hr", "img":
+		return
+	}
+	for _, child := range node.Children {
+		renderArticle(out, child, base)
+	}
+	out.WriteString("</" + node.Tag + ">")
+}
+
+func resolveArticleURL(base *neturl.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	parsed, err := neturl.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	return parsed.String()
+}
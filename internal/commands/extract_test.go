@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+// articleProse is long enough to pass minArticleChars on its own
+const articleProse = "Go 1.23 adds range-over-func iterators, which let any function that yields values be used in a for loop. " +
+	"The change touches the spec, the compiler and the standard library, and it took several years of discussion, " +
+	"prototypes and experiments before the design settled."
+
+func TestExtractArticle(t *testing.T) {
+	tests := []struct {
+		name        string
+		page        string
+		wantContain []string
+		wantMissing []string
+		wantErr     bool
+	}{
+		{
+			name: "boilerplate around the article",
+			page: `<!DOCTYPE html>
+<html><head><title>Blog</title><script>var x = "<p>not text</p>";</script></head>
+<body>
+<header><nav><a href="/">Home</a> <a href="/about">About</a></nav></header>
+<div class="cookie-banner"><p>We use cookies to improve your experience, please accept them all.</p></div>
+<article class="post">
+  <h1>Range over func</h1>
+  <p>` + articleProse + `</p>
+  <p>Iterators compose, so filters and maps can be chained without allocating intermediate slices.</p>
+</article>
+<div id="comments"><p>First! This is a long comment that should never be part of the article text.</p></div>
+<footer><p>Copyright 2024 Example Inc, all rights reserved, no part may be reproduced.</p></footer>
+</body></html>`,
+			wantContain: []string{"<h1>Range over func</h1>", "<p>Go 1.23 adds", "chained without allocating"},
+			wantMissing: []string{"Home", "cookies", "First!", "Copyright", "not text"},
+		},
+		{
+			name: "link-heavy sidebar with a content class",
+			page: `<html><body>
+<div class="content-sidebar">
+  <p><a href="/a">A very long list of related posts about Go iterators, generics, and more</a>,
+  <a href="/b">another link to an older post about range loops, closures, and yield</a>,
+  <a href="/c">and one more link about the history of the Go specification, again</a></p>
+</div>
+<div class="entry">
+  <p>` + articleProse + `</p>
+</div>
+</body></html>`,
+			wantContain: []string{"Go 1.23 adds"},
+			wantMissing: []string{"related posts"},
+		},
+		{
+			name: "malformed markup",
+			page: "<html><body><div class=\"story\">\n" +
+				"<p>Intro with a stray end tag</span> and a bare comparison: a < b && c > d.\n" +
+				"<p>" + articleProse + "\n" +
+				"<p>Unknown &bogus; entity, a non-breaking&nbsp;space and a bad \xff byte.\n" +
+				"<ul><li>one<li>two</ul>\n" +
+				"<p>The page ends without closing anything <b>at all",
+			wantContain: []string{
+				"<p>Intro with a stray end tag and a bare comparison: a &lt; b &amp;&amp; c &gt; d.\n</p>",
+				"Unknown &amp;bogus; entity, a non-breaking\u00a0space and a bad \ufffd byte.",
+				"<ul><li>one</li><li>two</li></ul>",
+				"<p>The page ends without closing anything <b>at all</b></p>",
+			},
+		},
+		{
+			name: "relative links and lazy images",
+			page: `<html><body><main>
+<p>` + articleProse + `</p>
+<p>See <a href="../notes/iterators.html">the notes</a> and <a href='https://go.dev/blog'>the blog</a>.</p>
+<figure><img src="data:image/gif;base64,R0lGOD" data-src="/img/range.png" alt="A range loop"><img src=plain.png></figure>
+</main></body></html>`,
+			wantContain: []string{
+				`<a href="https://example.com/posts/notes/iterators.html">the notes</a>`,
+				`<a href="https://go.dev/blog">the blog</a>`,
+				`<img src="https://example.com/img/range.png" alt="A range loop">`,
+				`<img src="https://example.com/posts/2024/plain.png" alt="">`,
+			},
+		},
+		{
+			name: "windows-1252 page",
+			page: "<html><head><meta charset=\"windows-1252\"></head><body><div class=\"post\"><p>Caf\xe9 \x93quotes\x94. " +
+				articleProse + "</p></div></body></html>",
+			wantContain: []string{"Café “quotes”."},
+		},
+		{
+			name:    "too little text",
+			page:    `<html><body><div class="post"><p>Just a short teaser, read more on the site.</p></div></body></html>`,
+			wantErr: true,
+		},
+		{
+			name:    "not html",
+			page:    "\x00\x01 binary <",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := extractArticle([]byte(test.page), "https://example.com/posts/2024/range.html")
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("extractArticle() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range test.wantContain {
+				if !strings.Contains(got, want) {
+					t.Errorf("extractArticle() is missing %q:\n%s", want, got)
+				}
+			}
+			for _, unwanted := range test.wantMissing {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("extractArticle() contains %q:\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{name: "stray end tag", page: "<p>a</span>b</p>", want: "<p>[a b]"},
+		{name: "unclosed elements", page: "<div><p>a<p>b", want: "<div>[<p>[a] <p>[b]]"},
+		{name: "implied list items", page: "<ul><li>a<li>b</ul>c", want: "<ul>[<li>[a] <li>[b]] c"},
+		{name: "literal less-than", page: "<p>1 <= 2 < 3</p>", want: "<p>[1 <= 2 < 3]"},
+		{name: "quoted greater-than", page: `<a title="x > y" href=/z>link</a>`, want: "<a href=/z title=x > y>[link]"},
+		{name: "void and self-closing", page: "<p>a<br>b<img src=x />c</p>", want: "<p>[a <br> b <img src=x> c]"},
+		{name: "cut-off tag", page: "<p>a</p><img src=", want: "<p>[a] <img src="},
+		{name: "doctype and comments", page: "<!DOCTYPE html><!-- <p>hidden</p> --><p>shown</p>", want: "<p>[shown]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := dumpHTML(parseHTML([]byte(test.page)).Children); got != test.want {
+				t.Errorf("parseHTML(%q) = %s, want %s", test.page, got, test.want)
+			}
+		})
+	}
+}
+
+// dumpHTML renders nodes compactly: elements as <tag attr=value>[children], text as is
+func dumpHTML(nodes []*htmlNode) string {
+	var parts []string
+	for _, node := range nodes {
+		if node.Tag == "" {
+			parts = append(parts, node.Text)
+			continue
+		}
+		part := "<" + node.Tag
+		for _, key := range []string{"href", "src", "title"} {
+			if value, ok := node.Attrs[key]; ok {
+				part += " " + key + "=" + value
+			}
+		}
+		part += ">"
+		if len(node.Children) > 0 {
+			part += "[" + dumpHTML(node.Children) + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
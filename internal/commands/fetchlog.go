@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"time"
+)
+
+// recordFetch writes a fetch to the fetch log. It runs even when ctx was cancelled
+// mid-fetch, since interrupted fetches are exactly the ones worth seeing later.
+func recordFetch(ctx context.Context, state *config.State, feed database.Feed, startedAt time.Time, resp feedResponse, result scrapeResult, fetchErr error, output scrapeOutput) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	params := database.CreateFetchLogParams{
+		ID:           uuid2.New(),
+		FeedID:       feed.ID,
+		StartedAt:    startedAt,
+		DurationMs:   time.Since(startedAt).Milliseconds(),
+		Bytes:        int64(len(resp.Body)),
+		NewPosts:     int32(result.Saved),
+		SkippedPosts: int32(result.Skipped),
+		FailedPosts:  int32(result.Failed),
+	}
+	if resp.StatusCode != 0 {
+		params.HttpStatus = sql.NullInt32{Int32: int32(resp.StatusCode), Valid: true}
+	}
+	if fetchErr != nil {
+		params.Error = sql.NullString{String: fetchErr.Error(), Valid: true}
+	}
+
+	if err := state.DB.CreateFetchLog(ctx, params); err != nil {
+		output.logf("Failed to record fetch of %s: %v", feed.Name, err)
+	}
+}
+
+// pruneFetchLog deletes fetch history older than the configured retention
+func pruneFetchLog(ctx context.Context, state *config.State) error {
+	retention, err := state.Config.FetchLogRetentionPeriod()
+	if err != nil {
+		return err
+	}
+	if _, err := state.DB.DeleteFetchLogsBefore(ctx, time.Now().UTC().Add(-retention)); err != nil {
+		return fmt.Errorf("failed to prune fetch log: %w", err)
+	}
+	return nil
+}
+
+// FetchLogHandler shows recent fetches and success rates, for one feed or all of them
+func FetchLogHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	fs := flag.NewFlagSet("fetchlog", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "number of recent fetches to show")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("invalid fetchlog flags: %w", err)
+	}
+	if *limit <= 0 {
+		return errors.New("limit must be a positive integer")
+	}
+
+	feedID := uuid2.NullUUID{}
+	if len(args) > 0 {
+		feed, err := findFeed(ctx, state, args[0])
+		if err != nil {
+			return err
+		}
+		feedID = uuid2.NullUUID{UUID: feed.ID, Valid: true}
+	}
+
+	if structuredOutput(state) {
+		logs, err := state.DB.GetRecentFetchLogs(ctx, database.GetRecentFetchLogsParams{
+			FeedID:   feedID,
+			RowLimit: int32(*limit),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get fetch log: %w", err)
+		}
+		rows := make([]fetchLogRow, 0, len(logs))
+		for _, entry := range logs {
+			row := fetchLogRow{
+				StartedAt:    entry.StartedAt,
+				Feed:         entry.FeedName,
+				DurationMs:   entry.DurationMs,
+				Bytes:        entry.Bytes,
+				NewPosts:     entry.NewPosts,
+				SkippedPosts: entry.SkippedPosts,
+				FailedPosts:  entry.FailedPosts,
+				Error:        entry.Error.String,
+			}
+			if entry.HttpStatus.Valid {
+				row.HTTPStatus = &entry.HttpStatus.Int32
+			}
+			rows = append(rows, row)
+		}
+		return writeRows(state, rows)
+	}
+
+	stats, err := state.DB.GetFetchLogStats(ctx, feedID)
+	if err != nil {
+		return fmt.Errorf("failed to get fetch log stats: %w", err)
+	}
+	for _, stat := range stats {
+		successRate := float64(stat.Fetches-stat.Failures) / float64(stat.Fetches) * 100
+		lastNew := "never"
+		lastNewAt, err := state.DB.GetLastFetchWithNewPosts(ctx, stat.FeedID)
+		if err == nil {
+			lastNew = lastNewAt.Format(time.RFC1123)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get last fetch with new posts for '%s': %w", stat.FeedName, err)
+		}
+		fmt.Printf("%s: %d fetches, %.1f%% successful, %d new posts, last new posts: %s\n",
+			stat.FeedName, stat.Fetches, successRate, stat.NewPosts, lastNew)
+	}
+	if len(stats) == 0 {
+		fmt.Println("No fetches recorded")
+		return nil
+	}
+
+	logs, err := state.DB.GetRecentFetchLogs(ctx, database.GetRecentFetchLogsParams{
+		FeedID:   feedID,
+		RowLimit: int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get fetch log: %w", err)
+	}
+
+	fmt.Println()
+	for _, entry := range logs {
+		status := "-"
+		if entry.HttpStatus.Valid {
+			status = fmt.Sprintf("%d", entry.HttpStatus.Int32)
+		}
+		fmt.Printf("%s  %s  status %s  %s  %d bytes  %d new, %d skipped, %d failed\n",
+			entry.StartedAt.Format(time.DateTime), entry.FeedName, status,
+			time.Duration(entry.DurationMs)*time.Millisecond, entry.Bytes,
+			entry.NewPosts, entry.SkippedPosts, entry.FailedPosts)
+		if entry.Error.Valid {
+			fmt.Printf("    error: %s\n", entry.Error.String)
+		}
+	}
+
+	return nil
+}
@@ -1,59 +1,133 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
-	"encoding/xml"
 	"errors"
+	"flag"
 	"fmt"
 	uuid2 "github.com/google/uuid"
 	"github.com/maevlava/Gator/internal/config"
 	"github.com/maevlava/Gator/internal/database"
 	"github.com/maevlava/Gator/internal/models"
-	"html"
 	"io"
 	"log"
 	"net/http"
+	neturl "net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// nullString maps an empty string to NULL
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
+
+// nullPath is nullString for file paths, stored absolute so fetches do not depend on the working directory
+func nullPath(path string) sql.NullString {
+	if path == "" {
+		return sql.NullString{}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return sql.NullString{String: path, Valid: true}
+}
+
 // Middleware Logged In Handlers
 
-func MiddlewareLoggedIn(handler func(state *config.State, cmd CLI, user database.User) error) func(state *config.State, cmd CLI) error {
+func MiddlewareLoggedIn(handler func(ctx context.Context, state *config.State, cmd CLI, user database.User) error) func(ctx context.Context, state *config.State, cmd CLI) error {
 
-	return func(state *config.State, cmd CLI) error {
-		user, err := state.DB.GetUser(context.Background(), state.Config.CurrentUser)
+	return func(ctx context.Context, state *config.State, cmd CLI) error {
+		user, err := state.DB.GetUser(ctx, state.Config.CurrentUser)
 		if err != nil {
 			return fmt.Errorf("failed to get current user '%v': %w", state.Config.CurrentUser, err)
 		}
-		err = handler(state, cmd, user)
+		err = handler(ctx, state, cmd, user)
 
 		return err
 	}
 }
 
 // AddFeedHandler, To create feed by a current user
-func AddFeedHandler(state *config.State, cmd CLI, currentUser database.User) error {
+func AddFeedHandler(ctx context.Context, state *config.State, cmd CLI, currentUser database.User) error {
+	// optional TLS settings for feeds behind a private CA or mutual TLS
+	fs := flag.NewFlagSet("addfeed", flag.ContinueOnError)
+	caFile := fs.String("ca-file", "", "PEM bundle of CAs trusted for this feed")
+	certFile := fs.String("cert", "", "PEM client certificate for mutual TLS")
+	keyFile := fs.String("key", "", "PEM client key for mutual TLS")
+	serverName := fs.String("server-name", "", "server name expected in the feed's certificate")
+	// optional credentials for private feeds
+	authType := fs.String("auth", "", "authentication scheme: basic, bearer or query")
+	authParam := fs.String("auth-param", "", "username for basic auth, parameter name for query auth")
+	secretFlag := fs.String("secret", "", "password, token or parameter value; '-' reads it from stdin")
+	fullText := fs.Bool("full-text", false, "download each new post's page and keep its main content")
+	language := fs.String("language", "", "language of the feed's posts for search, e.g. en or german; detected from the feed when unset")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("invalid addfeed flags: %w", err)
+	}
+
 	// need 2 args
-	if len(cmd.Args) < 2 {
+	if len(args) < 2 {
 		return errors.New("not enough arguments: feedName and feedUrl are required")
 	}
-	feedName := cmd.Args[0]
-	feedUrl := cmd.Args[1]
+	feedName := args[0]
+	feedUrl := args[1]
 
 	createTime := time.Now().UTC()
 	createFeedParams := database.CreateFeedParams{
-		ID:        uuid2.New(),
-		CreatedAt: createTime,
-		UpdatedAt: createTime,
-		Name:      feedName,
-		Url:       feedUrl,
-		UserID:    currentUser.ID,
+		ID:            uuid2.New(),
+		CreatedAt:     createTime,
+		UpdatedAt:     createTime,
+		Name:          feedName,
+		Url:           feedUrl,
+		UserID:        currentUser.ID,
+		TlsCaFile:     nullPath(*caFile),
+		TlsCertFile:   nullPath(*certFile),
+		TlsKeyFile:    nullPath(*keyFile),
+		TlsServerName: nullString(*serverName),
+		FetchFullText: *fullText,
+		SearchConfig:  searchConfigFor(*language),
 	}
 
-	feed, err := state.DB.CreateFeed(context.Background(), createFeedParams)
+	// fail early on unreadable certificates rather than on the first fetch
+	_, err = feedTLSConfig(database.Feed{
+		TlsCaFile:     createFeedParams.TlsCaFile,
+		TlsCertFile:   createFeedParams.TlsCertFile,
+		TlsKeyFile:    createFeedParams.TlsKeyFile,
+		TlsServerName: createFeedParams.TlsServerName,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid TLS settings for feed '%s': %w", feedName, err)
+	}
+
+	secret, err := readSecret(*secretFlag)
+	if err != nil {
+		return err
+	}
+	if err := validateCredentials(*authType, *authParam, secret); err != nil {
+		return fmt.Errorf("invalid credentials for feed '%s': %w", feedName, err)
+	}
+	if *authType != "" {
+		sealed, err := encryptSecret(state.Config.FeedCredentialsKey(), secret)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt credentials for feed '%s': %w", feedName, err)
+		}
+		createFeedParams.AuthType = nullString(*authType)
+		createFeedParams.AuthParam = nullString(*authParam)
+		createFeedParams.AuthSecret = sealed
+	}
+
+	feed, err := state.DB.CreateFeed(ctx, createFeedParams)
 	if err != nil {
 		return fmt.Errorf("failed to create feed '%s': %w", feedName, err)
 	}
@@ -68,7 +142,7 @@ func AddFeedHandler(state *config.State, cmd CLI, currentUser database.User) err
 		FeedID:    feed.ID,
 	}
 
-	_, err = state.DB.CreateFeedFollow(context.Background(), createFollowParams)
+	_, err = state.DB.CreateFeedFollow(ctx, createFollowParams)
 	if err != nil {
 		return err
 	}
@@ -76,8 +150,41 @@ func AddFeedHandler(state *config.State, cmd CLI, currentUser database.User) err
 	return nil
 }
 
+// FullTextHandler turns full article extraction on or off for an existing feed
+func FullTextHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	if len(cmd.Args) < 2 {
+		return errors.New("not enough arguments: feed url or name and on|off are required")
+	}
+
+	var enabled bool
+	switch cmd.Args[1] {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return fmt.Errorf("invalid setting '%s': must be on or off", cmd.Args[1])
+	}
+
+	feed, err := findFeed(ctx, state, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	err = state.DB.SetFeedFetchFullText(ctx, database.SetFeedFetchFullTextParams{
+		FetchFullText: enabled,
+		UpdatedAt:     time.Now().UTC(),
+		ID:            feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update feed '%s': %w", feed.Name, err)
+	}
+
+	fmt.Printf("Full text for %s: %s\n", feed.Name, cmd.Args[1])
+	return nil
+}
+
 // FollowHandler to create new Feed Follow for current user
-func FollowHandler(state *config.State, cmd CLI, currentUser database.User) error {
+func FollowHandler(ctx context.Context, state *config.State, cmd CLI, currentUser database.User) error {
 
 	// need 1 arg
 	var err error = nil
@@ -89,7 +196,7 @@ func FollowHandler(state *config.State, cmd CLI, currentUser database.User) erro
 
 	feedUrl := cmd.Args[0]
 
-	feed, err := state.DB.GetFeedByUrl(context.Background(), feedUrl)
+	feed, err := state.DB.GetFeedByUrl(ctx, feedUrl)
 	if err != nil {
 		return errors.New("failed to get feed by url")
 	}
@@ -102,7 +209,7 @@ func FollowHandler(state *config.State, cmd CLI, currentUser database.User) erro
 		FeedID:    feed.ID,
 		UserID:    currentUser.ID,
 	}
-	newFeedFollows, err := state.DB.CreateFeedFollow(context.Background(), feedFollowsParams)
+	newFeedFollows, err := state.DB.CreateFeedFollow(ctx, feedFollowsParams)
 	if err != nil {
 		return fmt.Errorf("failed to create feed follow for feed '%s' by user '%s': %w", feed.Name, currentUser.Name, err)
 	}
@@ -110,18 +217,50 @@ func FollowHandler(state *config.State, cmd CLI, currentUser database.User) erro
 	fmt.Println("FeedFollows created successfully")
 	fmt.Printf("Feed: %v\n", newFeedFollows)
 
-	return err
+	// the feed's stored posts may match searches saved before following it
+	return matchSavedSearchesForUser(ctx, state, currentUser)
 }
 
 // FollowingHandler return all the feeds current user are following
-func FollowingHandler(state *config.State, cmd CLI, currentUser database.User) error {
-	followedFeeds, err := state.DB.GetFollowedFeedsForUser(context.Background(), currentUser.ID)
+func FollowingHandler(ctx context.Context, state *config.State, cmd CLI, currentUser database.User) error {
+	followedFeeds, err := state.DB.GetFollowedFeedsForUser(ctx, currentUser.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get followed feeds for user '%s': %w", currentUser.Name, err)
 	}
 
+	savedSearches, err := state.DB.GetSavedSearchesForUser(ctx, currentUser.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get saved searches for user '%s': %w", currentUser.Name, err)
+	}
+
+	tmpl, err := outputTemplate(state, cmd.Name)
+	if err != nil {
+		return err
+	}
+	if structuredOutput(state) || tmpl != nil {
+		rows := make([]followingRow, 0, len(followedFeeds)+len(savedSearches))
+		for _, feed := range followedFeeds {
+			rows = append(rows, followingRow{Kind: "feed", Name: feed.Name, URL: feed.Url, Unread: feed.Unread})
+		}
+		for _, savedSearch := range savedSearches {
+			rows = append(rows, followingRow{Kind: "search", Name: savedSearch.Name, Query: savedSearch.Query, Unread: savedSearch.Unread})
+		}
+		if tmpl == nil {
+			return writeRows(state, rows)
+		}
+		for _, row := range rows {
+			if err := renderRow(tmpl, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	for _, feed := range followedFeeds {
-		fmt.Printf("- %s\n", feed.Name)
+		fmt.Printf("- %s (%d unread)\n", feed.Name, feed.Unread)
+	}
+	for _, savedSearch := range savedSearches {
+		fmt.Printf("- %s (search: %s, %d unread)\n", savedSearch.Name, savedSearch.Query, savedSearch.Unread)
 	}
 	fmt.Printf("%s\n", currentUser.Name)
 
@@ -129,18 +268,31 @@ func FollowingHandler(state *config.State, cmd CLI, currentUser database.User) e
 }
 
 // UnfollowHandler delete a feed follow and feed follow combination
-func UnfollowHandler(state *config.State, cmd CLI, currentUser database.User) error {
+func UnfollowHandler(ctx context.Context, state *config.State, cmd CLI, currentUser database.User) error {
 
 	if len(cmd.Args) < 1 {
-		return errors.New("not enough arguments: feedUrl is required")
+		return errors.New("not enough arguments: feedUrl or saved search name is required")
 	}
 	feedUrl := cmd.Args[0]
 
+	// saved searches are unfollowed by name
+	deleted, err := state.DB.DeleteSavedSearch(ctx, database.DeleteSavedSearchParams{
+		UserID: currentUser.ID,
+		Name:   feedUrl,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search for user '%s': %w", currentUser.Name, err)
+	}
+	if deleted > 0 {
+		fmt.Printf("Successfully deleted saved search: %s\n", feedUrl)
+		return nil
+	}
+
 	DeleteFeedFollowParams := database.DeleteFeedFollowForUserParams{
 		UserID: currentUser.ID,
 		Url:    feedUrl,
 	}
-	err := state.DB.DeleteFeedFollowForUser(context.Background(), DeleteFeedFollowParams)
+	err = state.DB.DeleteFeedFollowForUser(ctx, DeleteFeedFollowParams)
 	if err != nil {
 		return fmt.Errorf("failed to delete feed follow for user '%s': %w", currentUser.Name, err)
 	}
@@ -150,51 +302,546 @@ func UnfollowHandler(state *config.State, cmd CLI, currentUser database.User) er
 	return nil
 }
 
-func BrowseHandler(state *config.State, cmd CLI, user database.User) error {
-	limit := int32(2)
-	if len(cmd.Args) > 0 {
-		parsedLimit, err := strconv.Atoi(cmd.Args[0])
+func BrowseHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	fs := flag.NewFlagSet("browse", flag.ContinueOnError)
+	unread := fs.Bool("unread", true, "only show posts not yet marked read")
+	all := fs.Bool("all", false, "show read posts too")
+	pageFlag := fs.Int("page", 1, "page to show, counting from the newest posts")
+	beforeFlag := fs.String("before", "", "show posts older than this post handle")
+	afterFlag := fs.String("after", "", "show posts newer than this post handle")
+	feedFlag := fs.String("feed", "", "only show posts from this feed url or name, or saved search")
+	sinceFlag := fs.String("since", "", "only show posts from this date, or this long ago like 24h or 7d")
+	untilFlag := fs.String("until", "", "only show posts before this date, or this long ago")
+	sortFlag := fs.String("sort", "published", "order by published (first seen when undated), fetched or feed")
+	reverse := fs.Bool("reverse", false, "show the oldest posts first")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("invalid browse flags: %w", err)
+	}
+
+	limit := 2
+	if len(args) > 0 {
+		parsedLimit, err := strconv.Atoi(args[0])
 		if err != nil {
 			return err
 		}
 		if parsedLimit <= 0 {
 			return errors.New("limit must be a positive integer")
 		}
-		limit = int32(parsedLimit)
+		limit = parsedLimit
+	}
+	if *pageFlag <= 0 {
+		return errors.New("page must be a positive integer")
+	}
+	if *beforeFlag != "" && *afterFlag != "" {
+		return errors.New("--before and --after can't be combined")
+	}
+	if *pageFlag > 1 && (*beforeFlag != "" || *afterFlag != "") {
+		return errors.New("--page can't be combined with --before or --after")
 	}
 
-	posts, err := state.DB.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  limit,
-	})
+	switch *sortFlag {
+	case "published", "fetched", "feed":
+	default:
+		return fmt.Errorf("invalid sort '%s': must be published, fetched or feed", *sortFlag)
+	}
+
+	// a page runs against the listing order when paging back or listing oldest first
+	listing := postListing{
+		SortBy:    *sortFlag,
+		Backwards: *afterFlag != "" || (*reverse && *beforeFlag == ""),
+		GetPostsForUserParams: database.GetPostsForUserParams{
+			UserID:     user.ID,
+			UnreadOnly: *unread && !*all,
+		},
+	}
+	if *feedFlag != "" {
+		listing.FeedID, listing.SavedSearchID, err = findPostSource(ctx, state, user, *feedFlag)
+		if err != nil {
+			return err
+		}
+	}
+	if *sinceFlag != "" {
+		listing.Since, err = parseTimeBound(*sinceFlag, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if *untilFlag != "" {
+		listing.Until, err = parseTimeBound(*untilFlag, true)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	cursorFlag := *beforeFlag
+	if *afterFlag != "" {
+		cursorFlag = *afterFlag
+	}
+	if cursorFlag != "" {
+		post, err := findPost(ctx, state, cursorFlag)
+		if err != nil {
+			return err
+		}
+		feed, err := state.DB.GetFeed(ctx, post.FeedID)
+		if err != nil {
+			return fmt.Errorf("failed to get feed of post %d: %w", post.Handle, err)
+		}
+		listing.continueAfter(post.ID, feed.Name, post.PublishedAt, post.CreatedAt)
+	}
+
+	rules, err := loadRules(ctx, state, user)
+	if err != nil {
+		return err
+	}
+	tmpl, err := outputTemplate(state, cmd.Name)
 	if err != nil {
-		return fmt.Errorf("failed to get posts for user '%s': %w", user.Name, err)
+		return err
 	}
 
+	structured := structuredOutput(state)
+	interactive := !structured && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+	input := bufio.NewReader(os.Stdin)
+	for page := 1; ; page++ {
+		stories, boundary, more, err := browsePage(ctx, state, listing, limit, *reverse, rules)
+		if err != nil {
+			return fmt.Errorf("failed to get posts for user '%s': %w", user.Name, err)
+		}
+
+		// earlier pages are walked with the same cursors "more" would use
+		if page < *pageFlag {
+			if !more {
+				if structured {
+					return writeRows(state, []browseRow{})
+				}
+				fmt.Printf("No posts on page %d\n", *pageFlag)
+				return nil
+			}
+			nextBrowsePage(&listing, boundary)
+			continue
+		}
+
+		direction := "before"
+		if listing.Backwards {
+			direction = "after"
+		}
+		if structured {
+			rows := make([]browseRow, 0, len(stories))
+			for _, story := range stories {
+				rows = append(rows, newBrowseRow(story))
+			}
+			if err := writeRows(state, rows); err != nil {
+				return err
+			}
+			// the hint goes to stderr to keep stdout parseable
+			if more {
+				fmt.Fprintf(os.Stderr, "More: rerun with --%s %d\n", direction, boundary.Handle)
+			}
+			return nil
+		}
+
+		if len(stories) == 0 {
+			fmt.Println("No posts")
+			return nil
+		}
+		for _, story := range stories {
+			if tmpl == nil {
+				printStory(story)
+			} else if err := renderRow(tmpl, newBrowseRow(story)); err != nil {
+				return err
+			}
+		}
+		if !more {
+			return nil
+		}
+		if !interactive {
+			fmt.Printf("More: rerun with --%s %d\n", direction, boundary.Handle)
+			return nil
+		}
+		fmt.Print("-- more (Enter for next page, q to quit) --")
+		answer, err := input.ReadString('\n')
+		if err != nil || strings.TrimSpace(strings.ToLower(answer)) == "q" {
+			return nil
+		}
+		nextBrowsePage(&listing, boundary)
+	}
+}
+
+// postListing pages through a user's posts in one order with the GetPostsForUser queries.
+// Its Since and Until are left zero for no bound; the cursor fields are set by posts.
+type postListing struct {
+	database.GetPostsForUserParams
+	// SortBy is published (first seen when undated), fetched or feed
+	SortBy string
+	// Backwards runs against the order: oldest first, and feeds from last to first
+	Backwards bool
+	// Cursor is the post the next page continues past; without one the listing starts at its first post
+	Cursor *postCursor
+}
+
+// postCursor is a post's position in a listing order
+type postCursor struct {
+	Feed string
+	Time time.Time
+	ID   uuid2.UUID
+}
+
+// listingEnd is past every post's time
+var listingEnd = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// continueAfter makes the next page start right past the given post
+func (l *postListing) continueAfter(id uuid2.UUID, feedName string, publishedAt sql.NullTime, createdAt time.Time) {
+	cursorTime := createdAt
+	if l.SortBy != "fetched" && publishedAt.Valid {
+		cursorTime = publishedAt.Time
+	}
+	l.Cursor = &postCursor{Feed: feedName, Time: cursorTime, ID: id}
+}
+
+// nextBrowsePage moves the listing's cursor past boundary, in the direction the last page was fetched
+func nextBrowsePage(listing *postListing, boundary database.GetPostsForUserRow) {
+	listing.continueAfter(boundary.ID, boundary.FeedName, boundary.PublishedAt, boundary.CreatedAt)
+}
+
+// posts fetches the next rowLimit posts of the listing. Each order and direction has its own query
+// so that its ORDER BY and cursor comparison match the index they page through.
+func (l postListing) posts(ctx context.Context, db *database.Queries, rowLimit int32) ([]database.GetPostsForUserRow, error) {
+	params := l.GetPostsForUserParams
+	params.RowLimit = rowLimit
+	if params.Until.IsZero() {
+		params.Until = listingEnd
+	}
+	switch {
+	case l.Cursor != nil:
+		params.CursorTime, params.CursorID = l.Cursor.Time, l.Cursor.ID
+	case l.Backwards:
+		params.CursorTime, params.CursorID = time.Time{}, uuid2.Nil
+	default:
+		params.CursorTime, params.CursorID = listingEnd, uuid2.Max
+	}
+
+	switch l.SortBy {
+	case "fetched":
+		if l.Backwards {
+			return postRows(db.GetPostsForUserByFetchedBackwards(ctx, database.GetPostsForUserByFetchedBackwardsParams(params)))
+		}
+		return postRows(db.GetPostsForUserByFetched(ctx, database.GetPostsForUserByFetchedParams(params)))
+	case "feed":
+		// no index orders by feed name, so a listing without a cursor simply has no cursor feed
+		cursorFeed := sql.NullString{}
+		if l.Cursor != nil {
+			cursorFeed = sql.NullString{String: l.Cursor.Feed, Valid: true}
+		}
+		if l.Backwards {
+			return postRows(db.GetPostsForUserByFeedBackwards(ctx, database.GetPostsForUserByFeedBackwardsParams{
+				UserID:        params.UserID,
+				UnreadOnly:    params.UnreadOnly,
+				FeedID:        params.FeedID,
+				SavedSearchID: params.SavedSearchID,
+				Since:         params.Since,
+				Until:         params.Until,
+				CursorFeed:    cursorFeed,
+				CursorTime:    params.CursorTime,
+				CursorID:      params.CursorID,
+				RowLimit:      params.RowLimit,
+			}))
+		}
+		return postRows(db.GetPostsForUserByFeed(ctx, database.GetPostsForUserByFeedParams{
+			UserID:        params.UserID,
+			UnreadOnly:    params.UnreadOnly,
+			FeedID:        params.FeedID,
+			SavedSearchID: params.SavedSearchID,
+			Since:         params.Since,
+			Until:         params.Until,
+			CursorFeed:    cursorFeed,
+			CursorTime:    params.CursorTime,
+			CursorID:      params.CursorID,
+			RowLimit:      params.RowLimit,
+		}))
+	}
+	if l.Backwards {
+		return postRows(db.GetPostsForUserBackwards(ctx, database.GetPostsForUserBackwardsParams(params)))
+	}
+	return db.GetPostsForUser(ctx, params)
+}
+
+// postRows converts the rows of any GetPostsForUser query, which all share one shape
+func postRows[T database.GetPostsForUserBackwardsRow | database.GetPostsForUserByFetchedRow |
+	database.GetPostsForUserByFetchedBackwardsRow | database.GetPostsForUserByFeedRow |
+	database.GetPostsForUserByFeedBackwardsRow](rows []T, err error) ([]database.GetPostsForUserRow, error) {
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]database.GetPostsForUserRow, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, database.GetPostsForUserRow(row))
+	}
+	return posts, nil
+}
+
+// parseTimeBound reads a date or a "how long ago" duration such as 24h or 7d.
+// A bare date used as an upper bound covers the whole of that day.
+func parseTimeBound(value string, upper bool) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().UTC().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+
+	t, err := parseDate(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if _, err := time.Parse(time.DateOnly, value); err == nil && upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// browsePage fetches up to limit stories past the listing's cursor, in listing order. boundary is the
+// last post the page consumed, which is the cursor for the page beyond it. A page is only empty at
+// the end of the listing: when rules hide or read posts fill whole fetches, it reads on past them.
+func browsePage(ctx context.Context, state *config.State, listing postListing, limit int, reverse bool, rules *userRules) ([]story, database.GetPostsForUserRow, bool, error) {
+	for {
+		stories, boundary, more, err := fetchBrowsePage(ctx, state, listing, limit, reverse, rules)
+		if err != nil || len(stories) > 0 || !more {
+			return stories, boundary, more, err
+		}
+		nextBrowsePage(&listing, boundary)
+	}
+}
+
+// fetchBrowsePage is one fetch of browsePage, which may list nothing when every post it read was hidden
+func fetchBrowsePage(ctx context.Context, state *config.State, listing postListing, limit int, reverse bool, rules *userRules) ([]story, database.GetPostsForUserRow, bool, error) {
+	var boundary database.GetPostsForUserRow
+
+	// duplicates collapse into one story, so over-fetch to still fill the page
+	rowLimit := int32(limit * 3)
+	posts, err := listing.posts(ctx, state.DB, rowLimit)
+	if err != nil {
+		return nil, boundary, false, err
+	}
+	if len(posts) == 0 {
+		return nil, boundary, false, nil
+	}
+
+	// rules decide what is listed; the cursor still moves over everything fetched
+	var listed []database.GetPostsForUserRow
+	highlighted := make(map[uuid2.UUID]bool)
 	for _, post := range posts {
-		fmt.Printf("Title: %s\n", post.Title)
+		verdict, err := rules.apply(ctx, state, browseTarget(post), post.Read)
+		if err != nil {
+			return nil, boundary, false, err
+		}
+		if verdict.MarkRead {
+			post.Read = true
+		}
+		if verdict.Hide || (post.Read && listing.UnreadOnly) {
+			continue
+		}
+		highlighted[post.ID] = verdict.Highlight
+		listed = append(listed, post)
+	}
 
-		publishedStr := "N/A"
-		if post.PublishedAt.Valid {
-			publishedStr = post.PublishedAt.Time.Format(time.RFC1123)
+	stories, boundary, cut := cutBrowsePage(posts, groupDuplicatePosts(listed), limit)
+	for i := range stories {
+		stories[i].Highlight = highlighted[stories[i].Post.ID]
+	}
+	more := cut || len(posts) == int(rowLimit)
+
+	// pages fetched against the listing order are flipped back
+	if listing.Backwards != reverse {
+		slices.Reverse(stories)
+	}
+	return stories, boundary, more, nil
+}
+
+// cutBrowsePage keeps the first limit stories listed from the fetched posts and returns the post to
+// continue after, reporting whether stories were left over for the next page. A story's folded duplicates can sit
+// past later stories; the page is only cut where every kept story is complete, so none of them
+// comes back on the next page, even when that makes the page run over limit.
+func cutBrowsePage(posts []database.GetPostsForUserRow, stories []story, limit int) ([]story, database.GetPostsForUserRow, bool) {
+	if len(stories) <= limit {
+		return stories, posts[len(posts)-1], false
+	}
+	position := make(map[uuid2.UUID]int, len(posts))
+	for i, post := range posts {
+		position[post.ID] = i
+	}
+	// cuts[n] tells whether stories[:n] can be listed without stories[n:] on the same page
+	cuts := make([]bool, len(stories))
+	last := -1
+	for n, s := range stories {
+		cuts[n] = n > 0 && last < position[s.Post.ID]
+		last = max(last, position[s.Post.ID])
+		for _, duplicate := range s.Duplicates {
+			last = max(last, position[duplicate.ID])
 		}
-		fmt.Printf("Published: %s\n", publishedStr)
-		descriptionStr := "No description available."
-		if post.Description.Valid && post.Description.String != "" {
-			descriptionStr = post.Description.String
+	}
+
+	n := 0
+	for i := limit; i > 0 && n == 0; i-- {
+		if cuts[i] {
+			n = i
 		}
+	}
+	for i := limit + 1; i < len(stories) && n == 0; i++ {
+		if cuts[i] {
+			n = i
+		}
+	}
+	if n == 0 {
+		return stories, posts[len(posts)-1], false
+	}
+	// stop right before the first story that didn't fit
+	return stories[:n], posts[position[stories[n].Post.ID]-1], true
+}
 
-		fmt.Printf("Description: %s\n", descriptionStr)
-		fmt.Printf("URL: %s\n", post.Url)
+// printStory writes one browse entry
+func printStory(story story) {
+	post := story.Post
+	var marks string
+	if post.Updated {
+		marks += " [updated]"
+	}
+	if post.Read {
+		marks += " [read]"
+	}
+	if post.Starred {
+		marks += " [starred]"
 	}
+	marks += highlightMark(story.Highlight)
+	fmt.Printf("[%d] Title: %s%s\n", post.Handle, post.Title, marks)
 
-	return nil
+	publishedStr := fmt.Sprintf("N/A (first seen %s)", post.CreatedAt.Format(time.RFC1123))
+	if post.PublishedAt.Valid {
+		publishedStr = post.PublishedAt.Time.Format(time.RFC1123)
+	}
+	fmt.Printf("Published: %s\n", publishedStr)
+	fmt.Printf("Feed: %s\n", post.FeedName)
+	descriptionStr := "No description available."
+	if post.Description.Valid && post.Description.String != "" {
+		descriptionStr = post.Description.String
+	}
+
+	fmt.Printf("Description: %s\n", descriptionStr)
+	if excerpt := articleExcerpt(post.Article); excerpt != "" {
+		fmt.Printf("Article: %s\n", excerpt)
+	}
+	fmt.Printf("URL: %s\n", post.Url)
+	if len(story.AlsoIn) > 0 {
+		fmt.Printf("Also in: %s\n", strings.Join(story.AlsoIn, ", "))
+	}
+}
+
+// newBrowseRow is the structured output of a browse story
+func newBrowseRow(story story) browseRow {
+	post := story.Post
+	return browseRow{
+		postRow: postRow{
+			Handle:      post.Handle,
+			Title:       post.Title,
+			URL:         post.Url,
+			Feed:        post.FeedName,
+			Author:      post.Author,
+			Categories:  listOf(post.Categories),
+			Published:   publishedTime(post.PublishedAt),
+			FirstSeen:   post.CreatedAt,
+			Description: htmlToText(post.Description.String),
+			Read:        post.Read,
+			Starred:     post.Starred,
+			Highlighted: story.Highlight,
+			Article:     htmlToText(post.Article.String),
+		},
+		Updated: post.Updated,
+		AlsoIn:  listOf(story.AlsoIn),
+	}
+}
+
+// isTerminal reports whether f is attached to a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// story is a post together with the other feeds that carried it
+type story struct {
+	Post      database.GetPostsForUserRow
+	AlsoIn    []string
+	Highlight bool
+	// Duplicates are the posts of other feeds folded into this one
+	Duplicates []database.GetPostsForUserRow
+}
+
+// carriedBy reports whether the story already holds a post of feedID
+func (s story) carriedBy(feedID uuid2.UUID) bool {
+	if s.Post.FeedID == feedID {
+		return true
+	}
+	for _, duplicate := range s.Duplicates {
+		if duplicate.FeedID == feedID {
+			return true
+		}
+	}
+	return false
+}
+
+// groupDuplicatePosts folds posts of different feeds with the same canonical url or a near-identical
+// title into the first, newest, occurrence. Posts of the same feed are always separate stories.
+// Duplicates by canonical url are already folded across pages by GetPostsForUser; titles only
+// group within the page.
+func groupDuplicatePosts(posts []database.GetPostsForUserRow) []story {
+	var stories []story
+	for _, post := range posts {
+		merged := false
+		for i := range stories {
+			first := stories[i].Post
+			if stories[i].carriedBy(post.FeedID) {
+				continue
+			}
+			sameURL := post.CanonicalUrl != "" && post.CanonicalUrl == first.CanonicalUrl
+			if sameURL || similarTitles(post.Title, first.Title) {
+				stories[i].AlsoIn = appendUnique(stories[i].AlsoIn, first.FeedName, post.FeedName)
+				stories[i].Duplicates = append(stories[i].Duplicates, post)
+				merged = true
+				break
+			}
+		}
+		if merged {
+			continue
+		}
+
+		s := story{Post: post}
+		if post.AlsoIn != "" {
+			s.AlsoIn = strings.Split(post.AlsoIn, ", ")
+		}
+		stories = append(stories, s)
+	}
+	return stories
+}
+
+// appendUnique adds name to names unless it is already there or is the story's own feed
+func appendUnique(names []string, own, name string) []string {
+	if name == own {
+		return names
+	}
+	for _, existing := range names {
+		if existing == name {
+			return names
+		}
+	}
+	return append(names, name)
 }
 
 // Basic Handler
 
 // LoginHandler set current_user to user login
-func LoginHandler(state *config.State, cmd CLI) error {
+func LoginHandler(ctx context.Context, state *config.State, cmd CLI) error {
 
 	// handle 0 argument
 	if len(cmd.Args) == 0 {
@@ -202,7 +849,7 @@ func LoginHandler(state *config.State, cmd CLI) error {
 	}
 
 	// set current user to logged user
-	_, err := state.DB.GetUser(context.Background(), cmd.Args[0])
+	_, err := state.DB.GetUser(ctx, cmd.Args[0])
 	if err != nil {
 		fmt.Println("user does not exist")
 		os.Exit(1)
@@ -218,7 +865,7 @@ func LoginHandler(state *config.State, cmd CLI) error {
 }
 
 // RegisterHandler register user to table users in postgres database
-func RegisterHandler(state *config.State, cmd CLI) error {
+func RegisterHandler(ctx context.Context, state *config.State, cmd CLI) error {
 	// args for user's name
 	if len(cmd.Args) == 0 {
 		return errors.New("not enough arguments")
@@ -226,7 +873,7 @@ func RegisterHandler(state *config.State, cmd CLI) error {
 	name := cmd.Args[0]
 
 	// Check if the user already exists
-	_, err := state.DB.GetUser(context.Background(), name)
+	_, err := state.DB.GetUser(ctx, name)
 	if err == nil {
 		// User exists exit code 1
 		fmt.Println("User already exists")
@@ -244,7 +891,7 @@ func RegisterHandler(state *config.State, cmd CLI) error {
 	}
 
 	// register the name using db.queries
-	user, err := state.DB.CreateUser(context.Background(), createUserParams)
+	user, err := state.DB.CreateUser(ctx, createUserParams)
 	if err != nil {
 		return err
 	}
@@ -259,9 +906,9 @@ func RegisterHandler(state *config.State, cmd CLI) error {
 }
 
 // ResetHandler reset the users table (delete all the rows)
-func ResetHandler(state *config.State, cmd CLI) error {
+func ResetHandler(ctx context.Context, state *config.State, cmd CLI) error {
 	// tell state to delete all the rows
-	err := state.DB.DeleteAllUser(context.Background())
+	err := state.DB.DeleteAllUser(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to delete all users: %w", err)
 	}
@@ -269,11 +916,22 @@ func ResetHandler(state *config.State, cmd CLI) error {
 }
 
 // UserListHandler list all users in the users database
-func UserListHandler(state *config.State, cmd CLI) error {
-	users, err := state.DB.GetAllUsers(context.Background())
+func UserListHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	users, err := state.DB.GetAllUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get all users: %w", err)
 	}
+	if structuredOutput(state) {
+		rows := make([]userRow, 0, len(users))
+		for _, user := range users {
+			rows = append(rows, userRow{
+				Name:      user.Name,
+				Current:   user.Name == state.Config.CurrentUser,
+				CreatedAt: user.CreatedAt,
+			})
+		}
+		return writeRows(state, rows)
+	}
 	for _, user := range users {
 		if user.Name == state.Config.CurrentUser {
 			fmt.Printf("* %s (current)\n", user.Name)
@@ -286,12 +944,36 @@ func UserListHandler(state *config.State, cmd CLI) error {
 }
 
 // FeedListHandler, to print out all name, url, and creator  of the feed
-func FeedListHandler(state *config.State, cmd CLI) error {
-	feedListWithUser, err := state.DB.GetAllFeedsWithUser(context.Background())
+func FeedListHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	feedListWithUser, err := state.DB.GetAllFeedsWithUser(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get all feeds: %w", err)
 	}
 
+	tmpl, err := outputTemplate(state, cmd.Name)
+	if err != nil {
+		return err
+	}
+	if structuredOutput(state) || tmpl != nil {
+		rows := make([]feedRow, 0, len(feedListWithUser))
+		for _, feed := range feedListWithUser {
+			rows = append(rows, feedRow{
+				Name:      feed.Name,
+				URL:       feed.Url,
+				User:      feed.UserName,
+				CreatedAt: feed.CreatedAt,
+			})
+		}
+		if tmpl == nil {
+			return writeRows(state, rows)
+		}
+		for _, row := range rows {
+			if err := renderRow(tmpl, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	for _, feed := range feedListWithUser {
 		fmt.Printf("%s\n", feed.Name)
 		fmt.Printf("%s\n", feed.Url)
@@ -302,86 +984,180 @@ func FeedListHandler(state *config.State, cmd CLI) error {
 }
 
 // --- Aggregator Code ---
-// fetchAndParseFeed Takes a URL and returns the parsed feed or an error.
-func fetchAndParseFeed(url string) (*models.RSSFeed, error) {
-	client := &http.Client{Timeout: time.Second * 30}
+// fetchAndParseFeed Takes a feed and returns its parsed contents or an error.
+// The raw response is returned even on failure so it can be logged.
+// Credentials are never included in returned errors.
+func fetchAndParseFeed(ctx context.Context, cfg *config.Config, feed database.Feed) (*models.ParsedFeed, feedResponse, error) {
+	resp, err := fetchFeedBody(ctx, cfg, feed)
+	if err != nil {
+		return nil, resp, err
+	}
 
-	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	parsedFeed, err := parseFeed(resp.Body, feed.Url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %w", url, err)
+		return nil, resp, fmt.Errorf("failed to parse feed %s: %w", feed.Url, err)
 	}
+	return parsedFeed, resp, nil
+}
 
-	resp, err := client.Do(req)
+// maxResponseBytes bounds how much of a feed or article page is read
+const maxResponseBytes = 10 << 20
+
+// feedResponse is what a feed's server sent back; StatusCode is 0 when no response arrived
+type feedResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// fetchFeedBody downloads a feed document with the feed's TLS settings, credentials and host limits
+func fetchFeedBody(ctx context.Context, cfg *config.Config, feed database.Feed) (feedResponse, error) {
+	var fetched feedResponse
+	url := feed.Url
+	client, err := newFeedClient(cfg, feed)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch feed %s: %w", url, err)
+		return fetched, fmt.Errorf("failed to configure client for %s: %w", url, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch feed %s: status code %d", url, resp.StatusCode)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fetched, fmt.Errorf("failed to create request for %s: %w", url, err)
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	if err := applyCredentials(req, feed, cfg.FeedCredentialsKey()); err != nil {
+		return fetched, fmt.Errorf("failed to apply credentials for %s: %w", url, err)
+	}
+
+	// replayed fetches never reach the host, so there is nobody to be polite to
+	if cfg.ReplayDir == "" {
+		release, err := hostLimits.acquire(req.Context(), cfg, req.URL.Hostname())
+		if err != nil {
+			return fetched, fmt.Errorf("failed to wait for host limit of %s: %w", url, err)
+		}
+		defer release()
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read body for %s: %w", url, err)
+		// *url.Error repeats the request URL, which may carry a query secret
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fetched, fmt.Errorf("failed to fetch feed %s: %w", url, err)
 	}
+	defer resp.Body.Close()
+	fetched.StatusCode = resp.StatusCode
 
-	var rssFeed models.RSSFeed
-	err = xml.Unmarshal(bodyBytes, &rssFeed)
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	fetched.Body = bodyBytes
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal xml for %s: %w", url, err)
+		return fetched, fmt.Errorf("failed to read body for %s: %w", url, err)
 	}
 
-	rssFeed.Channel.Title = html.UnescapeString(rssFeed.Channel.Title)
-	rssFeed.Channel.Description = html.UnescapeString(rssFeed.Channel.Description)
-	for i := range rssFeed.Channel.Item {
-		rssFeed.Channel.Item[i].Title = html.UnescapeString(rssFeed.Channel.Item[i].Title)
-		rssFeed.Channel.Item[i].Description = html.UnescapeString(rssFeed.Channel.Item[i].Description)
+	if resp.StatusCode != http.StatusOK {
+		return fetched, fmt.Errorf("failed to fetch feed %s: status code %d", url, resp.StatusCode)
 	}
+	return fetched, nil
+}
+
+// scrapeResult counts what a single scrape did with a feed's items
+type scrapeResult struct {
+	Saved   int
+	Skipped int
+	Updated int
+	Failed  int
+}
+
+// scrapeOutput is where fetching reports each post it saves or updates, and the problems it skips over
+type scrapeOutput struct {
+	out    io.Writer
+	logger *log.Logger
+}
+
+var (
+	// stdScrapeOutput reports posts on stdout and problems through the standard logger
+	stdScrapeOutput = scrapeOutput{out: os.Stdout, logger: log.Default()}
+	// quietScrapeOutput reports nothing, for callers that own the terminal
+	quietScrapeOutput = scrapeOutput{out: io.Discard, logger: log.New(io.Discard, "", 0)}
+)
 
-	return &rssFeed, nil
+func (o scrapeOutput) printf(format string, args ...any) {
+	fmt.Fprintf(o.out, format, args...)
 }
 
-// scrapeFeeds
-func scrapeFeeds(db *database.Queries) error {
-	feed, err := db.GetNextFeedToFetch(context.Background())
+func (o scrapeOutput) logf(format string, args ...any) {
+	o.logger.Printf(format, args...)
+}
+
+// scrapeFeeds fetches the feed that has waited longest and saves its new posts.
+func scrapeFeeds(ctx context.Context, state *config.State) (scrapeResult, error) {
+	feed, err := state.DB.GetNextFeedToFetch(ctx)
 	if err != nil {
-		return fmt.Errorf("db.GetNextFeedToFetch: %w", err)
+		return scrapeResult{}, fmt.Errorf("db.GetNextFeedToFetch: %w", err)
 	}
+	return scrapeFeed(ctx, state, feed, stdScrapeOutput)
+}
+
+// scrapeFeed marks feed as fetched, fetches it, saves its new posts and records the fetch in the fetch log.
+// It stops between posts once ctx is done.
+func scrapeFeed(ctx context.Context, state *config.State, feed database.Feed, output scrapeOutput) (scrapeResult, error) {
+	startedAt := time.Now().UTC()
+	result, resp, err := fetchAndSaveFeed(ctx, state, feed, output)
+	recordFetch(ctx, state, feed, startedAt, resp, result, err, output)
+	return result, err
+}
+
+func fetchAndSaveFeed(ctx context.Context, state *config.State, feed database.Feed, output scrapeOutput) (scrapeResult, feedResponse, error) {
+	var result scrapeResult
+	db := state.DB
 
 	now := time.Now().UTC()
 	markParams := database.MarkFeedFetchedParams{
 		ID:            feed.ID,
 		LastFetchedAt: sql.NullTime{Time: now, Valid: true},
 	}
-	err = db.MarkFeedFetched(context.Background(), markParams)
+	err := db.MarkFeedFetched(ctx, markParams)
 	if err != nil {
-		return fmt.Errorf("db.MarkFeedFetched feed ID %s: %w", feed.ID, err)
+		return result, feedResponse{}, fmt.Errorf("db.MarkFeedFetched feed ID %s: %w", feed.ID, err)
 	}
 
-	parsedFeed, err := fetchAndParseFeed(feed.Url)
+	parsedFeed, resp, err := fetchAndParseFeed(ctx, state.Config, feed)
 	if err != nil {
-		return fmt.Errorf("fetchAndParseFeedA %s: %w", feed.Url, err)
+		return result, resp, fmt.Errorf("fetchAndParseFeedA %s: %w", feed.Url, err)
 	}
 
-	processedCount := 0
-	skippedCount := 0
-	for _, item := range parsedFeed.Channel.Item {
+	if err := detectSearchConfig(ctx, state, feed, parsedFeed); err != nil {
+		output.logf("Search language for %s not updated: %v", feed.Name, err)
+	}
 
-		publishedAt := sql.NullTime{}
-		dateString := item.PubDate
+	if parsedFeed.HubURL != "" && webSub != nil {
+		if err := webSub.ensureSubscribed(ctx, feed, parsedFeed); err != nil {
+			output.logf("WebSub subscription for %s failed: %v", feed.Name, err)
+		}
+	}
 
-		if dateString != "" {
-			parsedTime, err := time.Parse(time.RFC1123Z, dateString)
-			if err != nil {
-				parsedTime, err = time.Parse(time.RFC1123, dateString)
-			}
+	result, err = savePosts(ctx, state, feed, parsedFeed.Items, output)
+	return result, resp, err
+}
 
-			if err == nil {
-				publishedAt = sql.NullTime{Time: parsedTime.UTC(), Valid: true}
-			} else {
-				return fmt.Errorf("failed to parse date %s: %w", dateString, err)
-			}
+// savePosts stores a feed's items, whether polled or pushed by a WebSub hub.
+// It stops between posts once ctx is done.
+func savePosts(ctx context.Context, state *config.State, feed database.Feed, items []models.ParsedItem, output scrapeOutput) (scrapeResult, error) {
+	var result scrapeResult
+	db := state.DB
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		for _, warning := range item.Warnings {
+			output.logf("Post '%s' in %s: %s", item.Title, feed.Name, warning)
+		}
+
+		publishedAt := sql.NullTime{}
+		if !item.Published.IsZero() {
+			publishedAt = sql.NullTime{Time: item.Published, Valid: true}
 		}
 
 		description := sql.NullString{}
@@ -389,45 +1165,191 @@ func scrapeFeeds(db *database.Queries) error {
 			description = sql.NullString{String: item.Description, Valid: true}
 		}
 
-		postUrl := item.Link
+		postUrl := normalizeURL(item.Link, state.Config.TrackingParamList())
 		if postUrl == "" {
-			log.Printf("Skipping post '%s' - missing URL", item.Title)
-			skippedCount++
+			output.logf("Skipping post '%s' - missing URL", item.Title)
+			result.Skipped++
 			continue
 		}
 
+		content := nullString(cleanContent(item.Content))
+		hash := contentHash(item.Title, description.String, content.String)
+
+		// NULL arrays aren't allowed, an item without categories has an empty list
+		categories := item.Categories
+		if categories == nil {
+			categories = []string{}
+		}
+
 		createParams := database.CreatePostParams{
-			ID:          uuid2.New(),
-			CreatedAt:   time.Now().UTC(),
-			UpdatedAt:   time.Now().UTC(),
-			Title:       item.Title,
-			Url:         postUrl,
-			Description: description,
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
+			ID:           uuid2.New(),
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
+			Title:        item.Title,
+			Url:          postUrl,
+			Description:  description,
+			PublishedAt:  publishedAt,
+			FeedID:       feed.ID,
+			Content:      content,
+			ContentHash:  hash,
+			CanonicalUrl: canonicalURL(postUrl),
+			Author:       item.Author,
+			Categories:   categories,
 		}
 
-		_, err = db.CreatePost(context.Background(), createParams)
+		post, err := db.CreatePost(ctx, createParams)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				skippedCount++
+				updated, err := updateChangedPost(ctx, state, feed, createParams, output)
+				if err != nil {
+					output.logf("Failed to update post '%s' (%s): %v", item.Title, postUrl, err)
+					result.Failed++
+				} else if updated {
+					result.Updated++
+					output.printf("   - Post Updated: %s\n", item.Title)
+				} else {
+					result.Skipped++
+				}
 				continue
 			}
-			log.Printf("Failed to create post '%s' (%s): %v", item.Title, postUrl, err)
+			output.logf("Failed to create post '%s' (%s): %v", item.Title, postUrl, err)
+			result.Failed++
 			continue
 		}
-		processedCount++
-		fmt.Printf("   - Post Saved: %s\n", item.Title) // Kept this print for user feedback
+		result.Saved++
+		output.printf("   - Post Saved: %s\n", item.Title) // Kept this print for user feedback
+
+		if feed.FetchFullText {
+			saveArticle(ctx, state, feed, post, output)
+		}
+		matchSavedSearches(ctx, state, post, output)
 	}
-	return nil
+	return result, nil
 }
 
-// AggHandler runs the main feed aggregation loop.
-func AggHandler(state *config.State, cmd CLI) error {
-	if len(cmd.Args) < 1 {
+// saveArticle stores the extracted page of a newly saved post; failures leave the feed's own content in place
+func saveArticle(ctx context.Context, state *config.State, feed database.Feed, post database.Post, output scrapeOutput) {
+	article, err := fetchArticle(ctx, state.Config, feed, post.Url)
+	if err != nil {
+		output.logf("Failed to fetch full text of '%s': %v", post.Title, err)
+		return
+	}
+	err = state.DB.SetPostArticle(ctx, database.SetPostArticleParams{
+		Article: nullString(article),
+		ID:      post.ID,
+	})
+	if err != nil {
+		output.logf("Failed to save full text of '%s': %v", post.Title, err)
+	}
+}
+
+// updateChangedPost replaces a stored post whose content changed at the publisher,
+// keeping the previous version as a revision. It reports whether anything changed.
+func updateChangedPost(ctx context.Context, state *config.State, feed database.Feed, post database.CreatePostParams, output scrapeOutput) (bool, error) {
+	existing, err := state.DB.GetPostByUrl(ctx, post.Url)
+	if err != nil {
+		return false, err
+	}
+	// another feed carries the same story; remember it as a source, its publisher owns the text
+	if existing.FeedID != feed.ID {
+		added, err := state.DB.CreatePostSource(ctx, database.CreatePostSourceParams{
+			PostID:    existing.ID,
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return false, err
+		}
+		// followers of this feed may have saved searches the post matches
+		if added > 0 {
+			matchSavedSearches(ctx, state, existing, output)
+		}
+		return false, nil
+	}
+
+	if existing.ContentHash == "" {
+		// stored before content and hashes were kept, so there is nothing to compare with;
+		// the current version becomes the baseline without a revision
+		err := state.DB.SetPostContent(ctx, database.SetPostContentParams{
+			Title:       post.Title,
+			Description: post.Description,
+			Content:     post.Content,
+			ContentHash: post.ContentHash,
+			PublishedAt: post.PublishedAt,
+			Author:      post.Author,
+			Categories:  post.Categories,
+			ID:          existing.ID,
+		})
+		if err != nil {
+			return false, err
+		}
+		rematchSavedSearches(ctx, state, existing, output)
+		return false, nil
+	}
+	if existing.ContentHash == post.ContentHash {
+		return false, nil
+	}
+
+	updated, err := state.DB.UpdatePostWithRevision(ctx, database.UpdatePostWithRevisionParams{
+		ID:          existing.ID,
+		RevisionID:  uuid2.New(),
+		UpdatedAt:   time.Now().UTC(),
+		Title:       post.Title,
+		Description: post.Description,
+		Content:     post.Content,
+		ContentHash: post.ContentHash,
+		PublishedAt: post.PublishedAt,
+		Author:      post.Author,
+		Categories:  post.Categories,
+	})
+	if err != nil {
+		return false, err
+	}
+	rematchSavedSearches(ctx, state, updated, output)
+	return true, nil
+}
+
+// canonicalBackfillBatch is how many posts backfillCanonicalURLs updates per query
+const canonicalBackfillBatch = 500
+
+// backfillCanonicalURLs gives posts stored before canonical urls existed theirs, so they group with their duplicates
+func backfillCanonicalURLs(ctx context.Context, state *config.State) error {
+	for {
+		posts, err := state.DB.GetPostsWithoutCanonicalUrl(ctx, canonicalBackfillBatch)
+		if err != nil {
+			return fmt.Errorf("failed to get posts without canonical urls: %w", err)
+		}
+		for _, post := range posts {
+			err := state.DB.SetPostCanonicalUrl(ctx, database.SetPostCanonicalUrlParams{
+				CanonicalUrl: canonicalURL(normalizeURL(post.Url, state.Config.TrackingParamList())),
+				ID:           post.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to set canonical url of %s: %w", post.Url, err)
+			}
+		}
+		if len(posts) < canonicalBackfillBatch {
+			return nil
+		}
+	}
+}
+
+// aggShutdownGrace is how long in-flight feeds may run after SIGINT/SIGTERM
+const aggShutdownGrace = 10 * time.Second
+
+// AggHandler runs the main feed aggregation loop until ctx is cancelled.
+func AggHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	fs := flag.NewFlagSet("agg", flag.ContinueOnError)
+	grace := fs.Duration("grace", aggShutdownGrace, "how long in-flight feeds may run after a stop signal")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("invalid agg flags: %w", err)
+	}
+
+	if len(args) < 1 {
 		return errors.New("time_between_reqs argument required (e.g., '1m', '30s')")
 	}
-	durationStr := cmd.Args[0]
+	durationStr := args[0]
 	timeBetweenRequests, err := time.ParseDuration(durationStr)
 	if err != nil {
 		return fmt.Errorf("invalid duration format '%s': %w", durationStr, err)
@@ -438,18 +1360,379 @@ func AggHandler(state *config.State, cmd CLI) error {
 		log.Printf("Duration is too short")
 	}
 
+	// work outlives ctx by the grace period so a stop signal never cuts off a feed mid-insert
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+	stopGrace := context.AfterFunc(ctx, func() {
+		log.Printf("Stopping, waiting up to %s for in-flight feeds", *grace)
+		time.AfterFunc(*grace, cancelWork)
+	})
+	defer stopGrace()
+
+	// hubs can only push to us while the callback listener is up, and a replay must stay offline
+	if state.Config.WebSubCallbackURL != "" && state.Config.WebSubListenAddr != "" && state.Config.ReplayDir == "" {
+		webSub = newWebSubscriber(state)
+		webSub.start()
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), *grace)
+			defer cancel()
+			_ = webSub.server.Shutdown(shutdownCtx)
+			webSub = nil
+		}()
+	}
+
+	if err := backfillCanonicalURLs(workCtx, state); err != nil {
+		log.Printf("%v", err)
+	}
+
 	ticker := time.NewTicker(timeBetweenRequests)
 	defer ticker.Stop()
 
-	if err := scrapeFeeds(state.DB); err != nil {
-		log.Printf("Initial scrape failed: %v", err)
+	var total scrapeResult
+	cycles, failedCycles := 0, 0
+	for {
+		result, err := scrapeFeeds(workCtx, state)
+		cycles++
+		total.Saved += result.Saved
+		total.Skipped += result.Skipped
+		total.Failed += result.Failed
+		if err != nil {
+			failedCycles++
+			log.Printf("Scraping failed during loop: %v", err)
+		}
+		if webSub != nil {
+			webSub.renewLeases(workCtx)
+		}
+		if err := pruneFetchLog(workCtx, state); err != nil {
+			log.Printf("%v", err)
+		}
+		// a replay must stay offline, so digests wait for a live run
+		if state.Config.ReplayDir == "" {
+			if err := sendDueDigests(workCtx, state); err != nil {
+				log.Printf("%v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Printf("Aggregator stopped after %d cycles (%d failed): %d posts saved, %d skipped, %d failed\n",
+				cycles, failedCycles, total.Saved, total.Skipped, total.Failed)
+			return nil
+		case <-ticker.C:
+		}
 	}
+}
 
-	for range ticker.C {
-		if err := scrapeFeeds(state.DB); err != nil {
-			log.Printf("Scraping failed during loop: %v", err)
+// FetchHandler scrapes the given feeds once, by url or name, and reports what each produced.
+func FetchHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	all := fs.Bool("all", false, "fetch every feed")
+	due := fs.Bool("due", false, "fetch feeds not fetched within --interval")
+	interval := fs.Duration("interval", time.Hour, "how long since the last fetch makes a feed due")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("invalid fetch flags: %w", err)
+	}
+
+	var feeds []database.Feed
+	switch {
+	case *all:
+		feeds, err = state.DB.GetAllFeed(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get all feeds: %w", err)
+		}
+	case *due:
+		cutoff := sql.NullTime{Time: time.Now().UTC().Add(-*interval), Valid: true}
+		feeds, err = state.DB.GetFeedsDueForFetch(ctx, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to get feeds due for fetch: %w", err)
+		}
+	case len(args) > 0:
+		for _, arg := range args {
+			feed, err := findFeed(ctx, state, arg)
+			if err != nil {
+				return err
+			}
+			feeds = append(feeds, feed)
 		}
+	default:
+		return errors.New("not enough arguments: feed urls or names, --all or --due are required")
+	}
+
+	if err := backfillCanonicalURLs(ctx, state); err != nil {
+		log.Printf("%v", err)
 	}
 
+	failed := 0
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			break
+		}
+		result, err := scrapeFeed(ctx, state, feed, stdScrapeOutput)
+		if err != nil {
+			failed++
+			fmt.Printf("%s: failed: %v\n", feed.Name, err)
+			continue
+		}
+		fmt.Printf("%s: %d new, %d skipped, %d updated\n", feed.Name, result.Saved, result.Skipped, result.Updated)
+	}
+
+	if err := pruneFetchLog(ctx, state); err != nil {
+		log.Printf("%v", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d feeds failed to fetch", failed, len(feeds))
+	}
 	return nil
 }
+
+// findFeed looks a feed up by url, falling back to its name
+func findFeed(ctx context.Context, state *config.State, urlOrName string) (database.Feed, error) {
+	feed, err := state.DB.GetFeedByUrl(ctx, urlOrName)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("failed to get feed '%s': %w", urlOrName, err)
+	}
+	feed, err = state.DB.GetFeedByName(ctx, urlOrName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Feed{}, fmt.Errorf("no feed with url or name '%s'", urlOrName)
+		}
+		return database.Feed{}, fmt.Errorf("failed to get feed '%s': %w", urlOrName, err)
+	}
+	return feed, nil
+}
+
+// loadFeedDocument reads a feed from a local file or fetches it from a url, without touching the database.
+// It returns the document and the url relative links should be resolved against.
+func loadFeedDocument(ctx context.Context, state *config.State, target string) ([]byte, string, error) {
+	path := strings.TrimPrefix(target, "file://")
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return body, "", nil
+	}
+
+	resp, err := fetchFeedBody(ctx, state.Config, database.Feed{Url: target})
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Body, target, nil
+}
+
+// PreviewHandler prints what a feed would give us without storing anything
+func PreviewHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: feed url or file is required")
+	}
+
+	body, baseURL, err := loadFeedDocument(ctx, state, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	feed, err := parseFeed(body, baseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", cmd.Args[0], err)
+	}
+
+	fmt.Printf("Format: %s\n", feed.Format)
+	fmt.Printf("Title: %s\n", feed.Title)
+	fmt.Printf("Link: %s\n", feed.Link)
+	fmt.Printf("Description: %s\n", feed.Description)
+	if feed.HubURL != "" {
+		fmt.Printf("Hub: %s\n", feed.HubURL)
+	}
+	for _, warning := range feed.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	fmt.Printf("Items: %d\n", len(feed.Items))
+
+	for i, item := range feed.Items {
+		fmt.Printf("\n[%d] %s\n", i+1, item.Title)
+		fmt.Printf("    URL: %s\n", item.Link)
+		if item.GUID != "" {
+			fmt.Printf("    GUID: %s\n", item.GUID)
+		}
+		publishedStr := "N/A"
+		if !item.Published.IsZero() {
+			publishedStr = item.Published.Format(time.RFC1123)
+		}
+		fmt.Printf("    Published: %s\n", publishedStr)
+		if item.Author != "" {
+			fmt.Printf("    Author: %s\n", item.Author)
+		}
+		if len(item.Categories) > 0 {
+			fmt.Printf("    Categories: %s\n", strings.Join(item.Categories, ", "))
+		}
+		if item.Description != "" {
+			fmt.Printf("    Description: %s\n", truncate(item.Description, 200))
+		}
+		for _, warning := range item.Warnings {
+			fmt.Printf("    Warning: %s\n", warning)
+		}
+	}
+
+	return nil
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// ValidateHandler checks a feed against its format's spec and prints graded diagnostics
+func ValidateHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: feed url or file is required")
+	}
+
+	body, baseURL, err := loadFeedDocument(ctx, state, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	format, diags := validateFeed(body, baseURL)
+	if format != "" {
+		fmt.Printf("Format: %s\n", format)
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, d := range diags {
+		if d.Severity == severityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+		if d.Line > 0 {
+			fmt.Printf("line %d: %s: %s\n", d.Line, d.Severity, d.Message)
+		} else {
+			fmt.Printf("%s: %s\n", d.Severity, d.Message)
+		}
+	}
+	fmt.Printf("%d errors, %d warnings\n", errorCount, warningCount)
+
+	if errorCount > 0 {
+		return fmt.Errorf("%s is not a valid feed", cmd.Args[0])
+	}
+	return nil
+}
+
+// findPost looks a post up by handle, id or url
+func findPost(ctx context.Context, state *config.State, idOrUrl string) (database.Post, error) {
+	var post database.Post
+	var err error
+	if handle, parseErr := strconv.ParseInt(idOrUrl, 10, 64); parseErr == nil {
+		post, err = state.DB.GetPostByHandle(ctx, handle)
+	} else if id, parseErr := uuid2.Parse(idOrUrl); parseErr == nil {
+		post, err = state.DB.GetPost(ctx, id)
+	} else {
+		post, err = state.DB.GetPostByUrl(ctx, idOrUrl)
+		if errors.Is(err, sql.ErrNoRows) {
+			// stored urls are normalized, the one given may not be
+			post, err = state.DB.GetPostByUrl(ctx, normalizeURL(idOrUrl, state.Config.TrackingParamList()))
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return post, fmt.Errorf("no post with handle, id or url '%s'", idOrUrl)
+	}
+	if err != nil {
+		return post, fmt.Errorf("failed to get post '%s': %w", idOrUrl, err)
+	}
+	return post, nil
+}
+
+// HistoryHandler shows every stored version of a post and what changed between them
+func HistoryHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: post handle, id or url is required")
+	}
+
+	post, err := findPost(ctx, state, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	revisions, err := state.DB.GetPostRevisions(ctx, post.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get revisions for post '%s': %w", post.Title, err)
+	}
+
+	if structuredOutput(state) {
+		rows := make([]versionRow, 0, len(revisions)+1)
+		from := post.CreatedAt
+		for i, revision := range revisions {
+			until := revision.CreatedAt
+			rows = append(rows, versionRow{
+				Version: i + 1,
+				Title:   revision.Title,
+				Body:    postText(revision.Description, revision.Content),
+				From:    revision.ValidFrom,
+				Until:   &until,
+			})
+			from = revision.CreatedAt
+		}
+		rows = append(rows, versionRow{
+			Version: len(revisions) + 1,
+			Title:   post.Title,
+			Body:    postText(post.Description, post.Content),
+			From:    from,
+		})
+		return writeRows(state, rows)
+	}
+
+	fmt.Printf("%s\n%s\n", post.Title, post.Url)
+	if len(revisions) == 0 {
+		fmt.Println("No earlier versions")
+		return nil
+	}
+
+	// versions in order, ending with the current one
+	type version struct {
+		title, body string
+		until       time.Time
+	}
+	var versions []version
+	for _, revision := range revisions {
+		versions = append(versions, version{
+			title: revision.Title,
+			body:  postText(revision.Description, revision.Content),
+			until: revision.CreatedAt,
+		})
+	}
+	versions = append(versions, version{title: post.Title, body: postText(post.Description, post.Content)})
+
+	for i := 1; i < len(versions); i++ {
+		prev, next := versions[i-1], versions[i]
+		fmt.Printf("\nRevision %d -> %d (changed %s)\n", i, i+1, prev.until.Format(time.RFC1123))
+		ops := diffLines(
+			append([]string{"Title: " + prev.title}, strings.Split(prev.body, "\n")...),
+			append([]string{"Title: " + next.title}, strings.Split(next.body, "\n")...),
+		)
+		for _, op := range ops {
+			if op.Kind != ' ' {
+				fmt.Printf("%c %s\n", op.Kind, op.Line)
+			}
+		}
+	}
+
+	return nil
+}
+
+// postText is the readable body of a post, preferring full content over the summary
+func postText(description, content sql.NullString) string {
+	if content.Valid && content.String != "" {
+		return htmlToText(content.String)
+	}
+	return htmlToText(description.String)
+}
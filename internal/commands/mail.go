@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/smtpsink"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds a whole SMTP conversation
+const smtpTimeout = 2 * time.Minute
+
+// digestMessage builds a multipart/alternative mail carrying d as plain text and HTML
+func digestMessage(from, to string, d digest) ([]byte, error) {
+	var text, html bytes.Buffer
+	if err := renderDigestText(&text, d); err != nil {
+		return nil, err
+	}
+	if err := renderDigest(&html, d, "html"); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address '%s': %w", from, err)
+	}
+	_, domain, _ := strings.Cut(fromAddress.Address, "@")
+
+	var msg bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, value)
+	}
+	header("From", fromAddress.String())
+	header("To", to)
+	header("Subject", mime.QEncoding.Encode("utf-8", digestSubject(d)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid2.New(), domain))
+	header("MIME-Version", "1.0")
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary()))
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func digestSubject(d digest) string {
+	if d.Total == 1 {
+		return "Gator digest: 1 unread post"
+	}
+	return fmt.Sprintf("Gator digest: %d unread posts", d.Total)
+}
+
+// smtpFrom returns the configured sender, failing when mail isn't set up
+func smtpFrom(cfg *config.Config) (string, error) {
+	if cfg.SMTP == nil || cfg.SMTP.Host == "" || cfg.SMTP.From == "" {
+		return "", errors.New("smtp is not configured: set smtp.host and smtp.from in the config file")
+	}
+	return cfg.SMTP.From, nil
+}
+
+// sendMail delivers msg to one recipient through the configured SMTP server
+func sendMail(ctx context.Context, cfg *config.Config, to string, msg []byte) error {
+	from, err := smtpFrom(cfg)
+	if err != nil {
+		return err
+	}
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("invalid from address '%s': %w", from, err)
+	}
+	toAddress, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid address '%s': %w", to, err)
+	}
+
+	server := cfg.SMTP
+	security := server.Security
+	if security == "" {
+		security = "starttls"
+	}
+	port := server.Port
+	if port == 0 {
+		port = map[string]int{"starttls": 587, "tls": 465, "none": 25}[security]
+	}
+	addr := net.JoinHostPort(server.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: server.Host}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	var conn net.Conn
+	switch security {
+	case "tls":
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	case "starttls", "none":
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	default:
+		return fmt.Errorf("invalid smtp security '%s': must be starttls, tls or none", security)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, server.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session with %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if security == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not offer STARTTLS; set smtp.security to none to send unencrypted", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS with %s: %w", addr, err)
+		}
+	}
+	if server.Username != "" {
+		// PlainAuth refuses to send the password unencrypted to anything but localhost
+		if err := client.Auth(smtp.PlainAuth("", server.Username, cfg.SMTPPassword(), server.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate with %s: %w", addr, err)
+		}
+	}
+
+	if err := client.Mail(fromAddress.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM rejected: %w", err)
+	}
+	if err := client.Rcpt(toAddress.Address); err != nil {
+		return fmt.Errorf("smtp RCPT TO %s rejected: %w", toAddress.Address, err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA rejected: %w", err)
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// MailSinkHandler runs a local SMTP server that keeps mail instead of delivering it, to preview digests:
+// mailsink [--listen 127.0.0.1:2525] [--dir DIR]
+func MailSinkHandler(ctx context.Context, state *config.State, cmd CLI) error {
+	fs := flag.NewFlagSet("mailsink", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:2525", "address to accept mail on")
+	dir := fs.String("dir", "", "directory to save each message to as an .eml file")
+	if _, err := parseFlags(fs, cmd.Args); err != nil {
+		return fmt.Errorf("invalid mailsink flags: %w", err)
+	}
+
+	sink, err := smtpsink.Listen(*listen, *dir)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+	fmt.Printf("Mail sink listening on %s\n", sink.Addr())
+	host, port, _ := net.SplitHostPort(sink.Addr())
+	fmt.Printf("Send to it with smtp set to {\"host\": %q, \"port\": %s, \"security\": \"none\"}\n", host, port)
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("Mail sink stopped after %d messages\n", len(sink.Messages()))
+			return nil
+		case message := <-sink.Received():
+			fmt.Printf("Mail from %s to %s, %d bytes\n", message.From, strings.Join(message.To, ", "), len(message.Data))
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/smtpsink"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendDigestToSink(t *testing.T) {
+	d := digest{
+		User:  "alice",
+		Since: time.Date(2026, 10, 18, 7, 0, 0, 0, time.UTC),
+		Total: 1,
+		Groups: []digestGroup{{
+			Name: "Go Blog",
+			Posts: []digestPost{{
+				Handle:  7,
+				Title:   "Range over func — café",
+				URL:     "https://go.dev/blog/range-functions",
+				Feed:    "Go Blog",
+				Date:    time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+				Excerpt: "Iterators arrive in Go 1.23.",
+			}},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		security string
+		username string
+		wantErr  string
+	}{
+		{name: "plain", security: "none"},
+		{name: "auth to localhost", security: "none", username: "alice"},
+		{name: "starttls refused", security: "starttls", wantErr: "does not offer STARTTLS"},
+		{name: "bad security", security: "ssl", wantErr: "invalid smtp security"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sink, err := smtpsink.Listen("127.0.0.1:0", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sink.Close()
+			host, portText, _ := net.SplitHostPort(sink.Addr())
+			port, _ := strconv.Atoi(portText)
+			cfg := &config.Config{SMTP: &config.SMTPConfig{
+				Host:     host,
+				Port:     port,
+				Security: test.security,
+				Username: test.username,
+				Password: "secret",
+				From:     "Gator <gator@example.com>",
+			}}
+
+			msg, err := digestMessage(cfg.SMTP.From, "alice@example.com", d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = sendMail(context.Background(), cfg, "alice@example.com", msg)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("sendMail error = %v, want one containing %q", err, test.wantErr)
+				}
+				if n := len(sink.Messages()); n != 0 {
+					t.Fatalf("sink received %d messages, want none", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			messages := sink.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("sink received %d messages, want 1", len(messages))
+			}
+			received := messages[0]
+			if received.From != "gator@example.com" || len(received.To) != 1 || received.To[0] != "alice@example.com" {
+				t.Fatalf("envelope = %s -> %v, want gator@example.com -> [alice@example.com]", received.From, received.To)
+			}
+
+			parsed, err := mail.ReadMessage(bytes.NewReader(received.Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subject := parsed.Header.Get("Subject"); subject != "Gator digest: 1 unread post" {
+				t.Errorf("Subject = %q", subject)
+			}
+			mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+			if err != nil || mediaType != "multipart/alternative" {
+				t.Fatalf("Content-Type = %q, %v", mediaType, err)
+			}
+			parts := multipart.NewReader(parsed.Body, params["boundary"])
+			var types []string
+			for {
+				part, err := parts.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, err := io.ReadAll(part)
+				if err != nil {
+					t.Fatal(err)
+				}
+				types = append(types, part.Header.Get("Content-Type"))
+				if !strings.Contains(string(body), "Range over func — café") {
+					t.Errorf("%s part is missing the post title:\n%s", part.Header.Get("Content-Type"), body)
+				}
+			}
+			if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+				t.Errorf("parts = %v, want text/plain then text/html", types)
+			}
+		})
+	}
+}
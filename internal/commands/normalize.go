@@ -0,0 +1,110 @@
+package commands
+
+import (
+	neturl "net/url"
+	"strings"
+	"unicode"
+)
+
+// normalizeURL cleans a post url before it is stored: lower-case scheme and host,
+// no default port, no fragment and no tracking parameters. Unparseable urls are kept as is.
+func normalizeURL(raw string, trackingParams []string) string {
+	u, err := neturl.Parse(strings.TrimSpace(raw))
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	u.Host = host
+	if port != "" {
+		u.Host = host + ":" + port
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for name := range query {
+			if isTrackingParam(name, trackingParams) {
+				query.Del(name)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+func isTrackingParam(name string, trackingParams []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range trackingParams {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalURL reduces a normalized url to a key shared by its http/https, www, AMP
+// and trailing slash variants. It is only used to group duplicates, never to open a post.
+func canonicalURL(normalized string) string {
+	u, err := neturl.Parse(normalized)
+	if err != nil || u.Host == "" {
+		return normalized
+	}
+
+	host := strings.TrimPrefix(strings.TrimPrefix(u.Host, "www."), "amp.")
+	path := strings.TrimSuffix(u.Path, "/")
+	path = strings.TrimSuffix(path, "/amp")
+	path = strings.TrimSuffix(path, ".amp")
+
+	query := u.Query()
+	query.Del("amp")
+	if strings.EqualFold(query.Get("outputType"), "amp") {
+		query.Del("outputType")
+	}
+	canonical := host + path
+	if len(query) > 0 {
+		// Encode sorts by key, so parameter order does not matter
+		canonical += "?" + query.Encode()
+	}
+	return canonical
+}
+
+// titleWords splits a title into lower-case words for similarity checks
+func titleWords(title string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
+
+// similarTitles reports whether two titles very likely name the same story
+func similarTitles(a, b string) bool {
+	wordsA, wordsB := titleWords(a), titleWords(b)
+	if len(wordsA) < 3 || len(wordsB) < 3 {
+		return false
+	}
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	union := len(wordsA) + len(wordsB) - shared
+	return float64(shared)/float64(union) >= 0.8
+}
@@ -0,0 +1,88 @@
+package commands
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tracking := []string{"utm_*", "fbclid", "Ref"}
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "HTTPS://Example.COM/Post", want: "https://example.com/Post"},
+		{raw: "http://example.com:80/a", want: "http://example.com/a"},
+		{raw: "https://example.com:443/a", want: "https://example.com/a"},
+		{raw: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{raw: "https://example.com", want: "https://example.com/"},
+		{raw: "https://example.com/a#comments", want: "https://example.com/a"},
+		{raw: "https://example.com/a?utm_source=rss&utm_Medium=feed&id=3", want: "https://example.com/a?id=3"},
+		{raw: "https://example.com/a?fbclid=x&ref=home", want: "https://example.com/a"},
+		{raw: "https://example.com/a?b=2&a=1", want: "https://example.com/a?a=1&b=2"},
+		{raw: "  https://example.com/a  ", want: "https://example.com/a"},
+		{raw: "/relative/path", want: "/relative/path"},
+		{raw: "mailto:someone@example.com", want: "mailto:someone@example.com"},
+		{raw: "http://[::1", want: "http://[::1"},
+	}
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			if got := normalizeURL(test.raw, tracking); got != test.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", test.raw, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "scheme", a: "http://example.com/a", b: "https://example.com/a", same: true},
+		{name: "www", a: "https://www.example.com/a", b: "https://example.com/a", same: true},
+		{name: "trailing slash", a: "https://example.com/a/", b: "https://example.com/a", same: true},
+		{name: "amp host", a: "https://amp.example.com/a", b: "https://example.com/a", same: true},
+		{name: "amp path", a: "https://example.com/a/amp", b: "https://example.com/a", same: true},
+		{name: "amp suffix", a: "https://example.com/a.amp", b: "https://example.com/a", same: true},
+		{name: "amp query", a: "https://example.com/a?amp=1", b: "https://example.com/a", same: true},
+		{name: "output type", a: "https://example.com/a?outputType=AMP", b: "https://example.com/a", same: true},
+		{name: "query order", a: "https://example.com/a?x=1&y=2", b: "https://example.com/a?y=2&x=1", same: true},
+		{name: "different path", a: "https://example.com/a", b: "https://example.com/b"},
+		{name: "different host", a: "https://example.com/a", b: "https://example.org/a"},
+		{name: "different query", a: "https://example.com/a?id=1", b: "https://example.com/a?id=2"},
+		{name: "other output type", a: "https://example.com/a?outputType=print", b: "https://example.com/a"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := canonicalURL(test.a), canonicalURL(test.b)
+			if (a == b) != test.same {
+				t.Errorf("canonicalURL(%q) = %q, canonicalURL(%q) = %q, same = %v, want %v", test.a, a, test.b, b, a == b, test.same)
+			}
+		})
+	}
+}
+
+func TestSimilarTitles(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "Go 1.23 is released", b: "Go 1.23 is released", want: true},
+		{a: "Go 1.23 Is Released!", b: "go 1.23 is released", want: true},
+		{a: "Rust 2024 edition ships with async closures", b: "Rust 2024 edition ships with async closures today", want: true},
+		{a: "Go 1.23 is released", b: "Go 1.22 is released"},
+		{a: "Apple announces new laptops", b: "Apple announces new phones"},
+		// two-word titles are too short to call
+		{a: "Weekly roundup", b: "Weekly roundup"},
+		{a: "", b: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.a+"|"+test.b, func(t *testing.T) {
+			if got := similarTitles(test.a, test.b); got != test.want {
+				t.Errorf("similarTitles(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+			}
+			if got := similarTitles(test.b, test.a); got != test.want {
+				t.Errorf("similarTitles(%q, %q) = %v, want %v", test.b, test.a, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/maevlava/Gator/internal/config"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Output formats selected with the global --output flag
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+)
+
+// OutputFormats are the values --output accepts
+var OutputFormats = []string{outputText, outputJSON, outputJSONL, outputCSV, outputTSV}
+
+// The rows listing commands emit outside text output. Field names and order are part of the
+// documented schema in the README: add fields at the end, never rename or remove them.
+
+type userRow struct {
+	Name      string    `json:"name"`
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type feedRow struct {
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	User      string    `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// followingRow is a followed feed or a saved search
+type followingRow struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Query  string `json:"query"`
+	Unread int64  `json:"unread"`
+}
+
+type postRow struct {
+	Handle      int64      `json:"handle"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Feed        string     `json:"feed"`
+	Author      string     `json:"author"`
+	Categories  []string   `json:"categories"`
+	Published   *time.Time `json:"published"`
+	FirstSeen   time.Time  `json:"first_seen"`
+	Description string     `json:"description"`
+	Read        bool       `json:"read"`
+	Starred     bool       `json:"starred"`
+	Highlighted bool       `json:"highlighted"`
+	Article     string     `json:"article"`
+}
+
+type browseRow struct {
+	postRow
+	Updated bool     `json:"updated"`
+	AlsoIn  []string `json:"also_in"`
+}
+
+type starredRow struct {
+	postRow
+	StarredAt time.Time `json:"starred_at"`
+}
+
+type laterRow struct {
+	postRow
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+type searchRow struct {
+	postRow
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// fetchLogRow is one fetch of a feed, newest first
+type fetchLogRow struct {
+	StartedAt    time.Time `json:"started_at"`
+	Feed         string    `json:"feed"`
+	HTTPStatus   *int32    `json:"http_status"`
+	DurationMs   int64     `json:"duration_ms"`
+	Bytes        int64     `json:"bytes"`
+	NewPosts     int32     `json:"new_posts"`
+	SkippedPosts int32     `json:"skipped_posts"`
+	FailedPosts  int32     `json:"failed_posts"`
+	Error        string    `json:"error"`
+}
+
+// versionRow is one version of a post, oldest first; the last is the current one
+type versionRow struct {
+	Version int        `json:"version"`
+	Title   string     `json:"title"`
+	Body    string     `json:"body"`
+	From    time.Time  `json:"from"`
+	Until   *time.Time `json:"until"`
+}
+
+type scheduleRow struct {
+	Handle    int64      `json:"handle"`
+	Frequency string     `json:"frequency"`
+	Weekday   string     `json:"weekday"`
+	At        string     `json:"at"`
+	Email     string     `json:"email"`
+	By        string     `json:"by"`
+	MarkRead  bool       `json:"mark_read"`
+	NextRun   time.Time  `json:"next_run"`
+	LastSent  *time.Time `json:"last_sent"`
+}
+
+type ruleRow struct {
+	Handle  int64  `json:"handle"`
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+	Feed    string `json:"feed"`
+}
+
+// structuredOutput reports whether listings should emit rows rather than text
+func structuredOutput(state *config.State) bool {
+	return state.Config.Output != "" && state.Config.Output != outputText
+}
+
+// publishedTime is a post's publication date, nil when the feed didn't give one
+func publishedTime(publishedAt sql.NullTime) *time.Time {
+	if !publishedAt.Valid {
+		return nil
+	}
+	return &publishedAt.Time
+}
+
+// listOf keeps empty lists as [] rather than null in JSON
+func listOf(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// writeRows prints rows to stdout in the selected structured format
+func writeRows[T any](state *config.State, rows []T) error {
+	return encodeRows(os.Stdout, state.Config.Output, rows)
+}
+
+func encodeRows[T any](out io.Writer, format string, rows []T) error {
+	switch format {
+	case outputJSON:
+		if rows == nil {
+			rows = []T{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+
+	case outputJSONL:
+		encoder := json.NewEncoder(out)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputCSV, outputTSV:
+		var header []string
+		collectColumns(reflect.TypeFor[T](), &header)
+		records := [][]string{header}
+		for _, row := range rows {
+			record, err := rowValues(row, header)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+
+		if format == outputCSV {
+			writer := csv.NewWriter(out)
+			return writer.WriteAll(records)
+		}
+		// TSV has no quoting, so separators inside values become spaces
+		clean := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+		for _, record := range records {
+			for i, value := range record {
+				record[i] = clean.Replace(value)
+			}
+			if _, err := fmt.Fprintln(out, strings.Join(record, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format '%s'", format)
+}
+
+// collectColumns lists the json names of t's fields, flattening embedded structs as encoding/json does
+func collectColumns(t reflect.Type, columns *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			collectColumns(field.Type, columns)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		*columns = append(*columns, name)
+	}
+}
+
+// rowValues renders row's columns for CSV and TSV from its JSON encoding, so both agree on
+// the schema: times stay RFC 3339, null becomes empty and lists are joined with "; "
+func rowValues(row any, columns []string) ([]string, error) {
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(columns))
+	for _, column := range columns {
+		switch value := fields[column].(type) {
+		case nil:
+			values = append(values, "")
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			values = append(values, strings.Join(items, "; "))
+		default:
+			values = append(values, fmt.Sprint(value))
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEncodeRows(t *testing.T) {
+	published := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	rows := []browseRow{
+		{
+			postRow: postRow{
+				Handle:     1,
+				Title:      "Tabs\tand \"quotes\"",
+				URL:        "https://example.com/a",
+				Feed:       "Example",
+				Categories: []string{"go", "web"},
+				Published:  &published,
+				FirstSeen:  published,
+				Read:       true,
+				Article:    "Full text",
+			},
+			AlsoIn: []string{},
+		},
+		{
+			postRow: postRow{
+				Handle:      2,
+				Title:       "Undated",
+				URL:         "https://example.com/b",
+				Feed:        "Example",
+				Categories:  []string{},
+				FirstSeen:   published,
+				Description: "line one\nline two",
+			},
+			AlsoIn: []string{"Mirror"},
+		},
+	}
+	header := "handle,title,url,feed,author,categories,published,first_seen,description,read,starred,highlighted,article,updated,also_in\n"
+
+	tests := []struct {
+		format  string
+		rows    []browseRow
+		want    string
+		wantErr bool
+	}{
+		{format: outputJSON, rows: rows[1:], want: `[
+  {
+    "handle": 2,
+    "title": "Undated",
+    "url": "https://example.com/b",
+    "feed": "Example",
+    "author": "",
+    "categories": [],
+    "published": null,
+    "first_seen": "2026-10-18T09:30:00Z",
+    "description": "line one\nline two",
+    "read": false,
+    "starred": false,
+    "highlighted": false,
+    "article": "",
+    "updated": false,
+    "also_in": [
+      "Mirror"
+    ]
+  }
+]
+`},
+		{format: outputJSON, want: "[]\n"},
+		{format: outputJSONL, rows: rows, want: `{"handle":1,"title":"Tabs\tand \"quotes\"","url":"https://example.com/a","feed":"Example","author":"","categories":["go","web"],"published":"2026-10-18T09:30:00Z","first_seen":"2026-10-18T09:30:00Z","description":"","read":true,"starred":false,"highlighted":false,"article":"Full text","updated":false,"also_in":[]}
+{"handle":2,"title":"Undated","url":"https://example.com/b","feed":"Example","author":"","categories":[],"published":null,"first_seen":"2026-10-18T09:30:00Z","description":"line one\nline two","read":false,"starred":false,"highlighted":false,"article":"","updated":false,"also_in":["Mirror"]}
+`},
+		{format: outputJSONL, want: ""},
+		{format: outputCSV, rows: rows, want: header +
+			"1,\"Tabs\tand \"\"quotes\"\"\",https://example.com/a,Example,,go; web,2026-10-18T09:30:00Z,2026-10-18T09:30:00Z,,true,false,false,Full text,false,\n" +
+			"2,Undated,https://example.com/b,Example,,,,2026-10-18T09:30:00Z,\"line one\nline two\",false,false,false,,false,Mirror\n"},
+		{format: outputCSV, want: header},
+		{format: outputTSV, rows: rows, want: "handle\ttitle\turl\tfeed\tauthor\tcategories\tpublished\tfirst_seen\tdescription\tread\tstarred\thighlighted\tarticle\tupdated\talso_in\n" +
+			"1\tTabs and \"quotes\"\thttps://example.com/a\tExample\t\tgo; web\t2026-10-18T09:30:00Z\t2026-10-18T09:30:00Z\t\ttrue\tfalse\tfalse\tFull text\tfalse\t\n" +
+			"2\tUndated\thttps://example.com/b\tExample\t\t\t\t2026-10-18T09:30:00Z\tline one line two\tfalse\tfalse\tfalse\t\tfalse\tMirror\n"},
+		{format: "xml", rows: rows, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var out bytes.Buffer
+			err := encodeRows(&out, test.format, test.rows)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("encodeRows() wrote %q, want an error", out.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.want {
+				t.Errorf("encodeRows() =\n%s\nwant\n%s", out.String(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/maevlava/Gator/internal/models"
+	"html"
+	"io"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// dateLayouts are tried in order; publishers are creative with RFC 822
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC3339Nano,
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC850,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 02 Jan 2006 15:04:05 Z",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"Mon, 2 January 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses a feed date in any layout we know about
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date format '%s'", value)
+}
+
+// detectFormat sniffs the document's format from its first significant bytes or root element
+func detectFormat(body []byte) (string, error) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(trimmed) == 0 {
+		return "", errors.New("empty document")
+	}
+	if trimmed[0] == '{' {
+		return models.FormatJSONFeed, nil
+	}
+
+	decoder := newXMLDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("not a feed: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(start.Name.Local) {
+		case "rss":
+			return models.FormatRSS2, nil
+		case "rdf":
+			return models.FormatRSS1, nil
+		case "feed":
+			return models.FormatAtom, nil
+		default:
+			return "", fmt.Errorf("not a feed: unexpected root element <%s>", start.Name.Local)
+		}
+	}
+}
+
+// parseFeed parses an RSS 2.0, RSS 1.0, Atom or JSON Feed document.
+// Relative links are resolved against the feed's own link, then against baseURL.
+func parseFeed(body []byte, baseURL string) (*models.ParsedFeed, error) {
+	format, err := detectFormat(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed *models.ParsedFeed
+	switch format {
+	case models.FormatRSS2:
+		feed, err = parseRSS(body)
+	case models.FormatRSS1:
+		feed, err = parseRDF(body)
+	case models.FormatAtom:
+		feed, err = parseAtom(body)
+	case models.FormatJSONFeed:
+		feed, err = parseJSONFeed(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", format, err)
+	}
+	feed.Format = format
+
+	base, _ := neturl.Parse(baseURL)
+	if feed.Link != "" {
+		if link, ok := resolveLink(base, feed.Link); ok {
+			feed.Link = link
+			base, _ = neturl.Parse(link)
+		}
+	}
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		item.Title = strings.TrimSpace(html.UnescapeString(item.Title))
+		item.Description = strings.TrimSpace(html.UnescapeString(item.Description))
+		item.Content = strings.TrimSpace(item.Content)
+		if item.Link == "" {
+			continue
+		}
+		if parsed, err := neturl.Parse(item.Link); err == nil && !parsed.IsAbs() {
+			resolved, ok := resolveLink(base, item.Link)
+			if !ok {
+				item.Warnings = append(item.Warnings, fmt.Sprintf("relative link '%s' could not be resolved", item.Link))
+				continue
+			}
+			item.Warnings = append(item.Warnings, fmt.Sprintf("relative link '%s' resolved to %s", item.Link, resolved))
+			item.Link = resolved
+		}
+	}
+	feed.Title = strings.TrimSpace(html.UnescapeString(feed.Title))
+	feed.Description = strings.TrimSpace(html.UnescapeString(feed.Description))
+	if feed.Title == "" {
+		feed.Warnings = append(feed.Warnings, "feed has no title")
+	}
+	if feed.Link == "" {
+		feed.Warnings = append(feed.Warnings, "feed has no website link")
+	}
+
+	return feed, nil
+}
+
+// resolveLink resolves ref against base, reporting false if the result is still relative
+func resolveLink(base *neturl.URL, ref string) (string, bool) {
+	parsed, err := neturl.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", false
+	}
+	if parsed.IsAbs() {
+		return parsed.String(), true
+	}
+	if base == nil || !base.IsAbs() {
+		return "", false
+	}
+	return base.ResolveReference(parsed).String(), true
+}
+
+func parseRSS(body []byte) (*models.ParsedFeed, error) {
+	var rss models.RSSFeed
+	if err := newXMLDecoder(bytes.NewReader(body)).Decode(&rss); err != nil {
+		return nil, err
+	}
+
+	feed := &models.ParsedFeed{
+		Title:       rss.Channel.Title,
+		Description: rss.Channel.Description,
+		Language:    strings.TrimSpace(rss.Channel.Language),
+	}
+	feed.Link, feed.SelfURL, feed.HubURL = splitLinks(rss.Channel.Links)
+	for _, item := range rss.Channel.Item {
+		feed.Items = append(feed.Items, convertRSSItem(item, item.PubDate))
+	}
+	return feed, nil
+}
+
+func parseRDF(body []byte) (*models.ParsedFeed, error) {
+	var rdf models.RDFFeed
+	if err := newXMLDecoder(bytes.NewReader(body)).Decode(&rdf); err != nil {
+		return nil, err
+	}
+
+	feed := &models.ParsedFeed{
+		Title:       rdf.Channel.Title,
+		Description: rdf.Channel.Description,
+		Language:    strings.TrimSpace(rdf.Channel.Language),
+	}
+	feed.Link, feed.SelfURL, feed.HubURL = splitLinks(rdf.Channel.Links)
+	for _, item := range rdf.Item {
+		feed.Items = append(feed.Items, convertRSSItem(item, item.Date))
+	}
+	return feed, nil
+}
+
+// convertRSSItem normalises an RSS 2.0 or 1.0 item; RSS 1.0 dates come from dc:date
+func convertRSSItem(item models.RSSItem, date string) models.ParsedItem {
+	parsed := models.ParsedItem{
+		Title:       item.Title,
+		GUID:        strings.TrimSpace(item.GUID),
+		Description: item.Description,
+		Content:     item.Content,
+		Author:      strings.TrimSpace(item.Author),
+		Categories:  append(trimAll(item.Categories), trimAll(item.Subjects)...),
+	}
+	parsed.Link, _, _ = splitLinks(item.Links)
+	if parsed.Author == "" {
+		parsed.Author = strings.TrimSpace(item.Creator)
+	}
+	if date == "" {
+		date = item.Date
+	}
+	if parsed.Link == "" && strings.HasPrefix(parsed.GUID, "http") {
+		parsed.Link = parsed.GUID
+		parsed.Warnings = append(parsed.Warnings, "missing link, using guid")
+	}
+	if parsed.Link == "" && item.Enclosure != nil && item.Enclosure.URL != "" {
+		parsed.Link = item.Enclosure.URL
+		parsed.Warnings = append(parsed.Warnings, "missing link, using enclosure url")
+	}
+	setPublished(&parsed, date)
+	return parsed
+}
+
+// splitLinks separates a plain RSS <link> from atom:link self and hub references
+func splitLinks(links []models.XMLLink) (link, self, hub string) {
+	for _, l := range links {
+		switch {
+		case l.Href == "" && link == "":
+			link = strings.TrimSpace(l.Value)
+		case l.Rel == "self":
+			self = l.Href
+		case l.Rel == "hub":
+			hub = l.Href
+		case (l.Rel == "" || l.Rel == "alternate") && link == "":
+			link = l.Href
+		}
+	}
+	return link, self, hub
+}
+
+func parseAtom(body []byte) (*models.ParsedFeed, error) {
+	var atom models.AtomFeed
+	if err := newXMLDecoder(bytes.NewReader(body)).Decode(&atom); err != nil {
+		return nil, err
+	}
+
+	feed := &models.ParsedFeed{
+		Title:       atomText(atom.Title),
+		Description: atomText(atom.Subtitle),
+		Language:    strings.TrimSpace(atom.Lang),
+	}
+	feed.Link, feed.SelfURL, feed.HubURL = splitLinks(atom.Links)
+	for _, entry := range atom.Entry {
+		item := models.ParsedItem{
+			Title:       atomText(entry.Title),
+			GUID:        strings.TrimSpace(entry.ID),
+			Description: atomText(entry.Summary),
+			Content:     atomMarkup(entry.Content),
+		}
+		item.Link, _, _ = splitLinks(entry.Links)
+		for _, author := range entry.Authors {
+			if author.Name != "" {
+				item.Author = strings.TrimSpace(author.Name)
+				break
+			}
+		}
+		for _, category := range entry.Categories {
+			if category.Term != "" {
+				item.Categories = append(item.Categories, strings.TrimSpace(category.Term))
+			}
+		}
+		date := entry.Published
+		if date == "" {
+			date = entry.Updated
+		}
+		setPublished(&item, date)
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// atomText flattens a text construct to plain text
+func atomText(text models.AtomText) string {
+	if text.Type == "xhtml" {
+		return strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(text.Inner, "")))
+	}
+	if text.Type == "html" {
+		return strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(text.Value, "")))
+	}
+	return strings.TrimSpace(text.Value)
+}
+
+// atomMarkup returns a content construct as HTML
+func atomMarkup(text models.AtomText) string {
+	switch text.Type {
+	case "xhtml":
+		return text.Inner
+	case "html":
+		return text.Value
+	default:
+		return html.EscapeString(text.Value)
+	}
+}
+
+func parseJSONFeed(body []byte) (*models.ParsedFeed, error) {
+	var jf models.JSONFeed
+	if err := json.Unmarshal(body, &jf); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(jf.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("unsupported version '%s'", jf.Version)
+	}
+
+	feed := &models.ParsedFeed{
+		Title:       jf.Title,
+		Link:        jf.HomePageURL,
+		Description: jf.Description,
+		Language:    strings.TrimSpace(jf.Language),
+		SelfURL:     jf.FeedURL,
+	}
+	for _, hub := range jf.Hubs {
+		if strings.EqualFold(hub.Type, "WebSub") {
+			feed.HubURL = hub.URL
+		}
+	}
+	for _, entry := range jf.Items {
+		item := models.ParsedItem{
+			Title:       entry.Title,
+			Link:        entry.URL,
+			GUID:        jsonFeedID(entry.ID),
+			Description: entry.Summary,
+			Content:     entry.ContentHTML,
+			Categories:  trimAll(entry.Tags),
+		}
+		if item.Content == "" && entry.ContentText != "" {
+			item.Content = html.EscapeString(entry.ContentText)
+		}
+		if item.Link == "" && entry.ExternalURL != "" {
+			item.Link = entry.ExternalURL
+			item.Warnings = append(item.Warnings, "missing url, using external_url")
+		}
+		if len(entry.Authors) > 0 {
+			item.Author = entry.Authors[0].Name
+		} else if entry.Author != nil {
+			item.Author = entry.Author.Name
+		}
+		date := entry.DatePublished
+		if date == "" {
+			date = entry.DateModified
+		}
+		setPublished(&item, date)
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// jsonFeedID accepts the string ids the spec requires and the numbers publishers send anyway
+func jsonFeedID(id any) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%v", v)
+	default:
+		return ""
+	}
+}
+
+// setPublished parses date into item, recording a warning instead of failing the feed
+func setPublished(item *models.ParsedItem, date string) {
+	if strings.TrimSpace(date) == "" {
+		item.Warnings = append(item.Warnings, "missing publication date")
+		return
+	}
+	published, err := parseDate(date)
+	if err != nil {
+		item.Warnings = append(item.Warnings, err.Error())
+		return
+	}
+	item.Published = published
+}
+
+func trimAll(values []string) []string {
+	var trimmed []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
+
+// newXMLDecoder returns a lenient decoder that understands the legacy encodings feeds still use
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// windows1252 maps the bytes 0x80-0x9F that differ from ISO-8859-1
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// charsetReader converts single-byte legacy encodings to UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		raw, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		cp1252 := strings.Contains(strings.ToLower(charset), "1252")
+		converted := make([]byte, 0, len(raw))
+		for _, b := range raw {
+			r := rune(b)
+			if cp1252 && b >= 0x80 && b <= 0x9f {
+				r = windows1252[b-0x80]
+			}
+			converted = utf8.AppendRune(converted, r)
+		}
+		return bytes.NewReader(converted), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset '%s'", charset)
+	}
+}
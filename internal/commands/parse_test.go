@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"github.com/maevlava/Gator/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		baseURL string
+		want    models.ParsedFeed
+		wantErr bool
+	}{
+		{
+			name: "rss 2.0",
+			body: `<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+  <title>Example &amp; Co</title>
+  <link>https://example.com/</link>
+  <atom:link rel="self" href="https://example.com/feed.xml"/>
+  <atom:link rel="hub" href="https://hub.example.com/"/>
+  <description>News</description>
+  <language>en</language>
+  <item>
+    <title>First post</title>
+    <link>https://example.com/first</link>
+    <guid>first</guid>
+    <description>&lt;p&gt;Hello&lt;/p&gt;</description>
+    <dc:creator> Ann </dc:creator>
+    <category>go</category>
+    <category> </category>
+    <pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate>
+  </item>
+  <item>
+    <title>Relative</title>
+    <link>/second</link>
+    <guid>https://example.com/guid</guid>
+  </item>
+</channel>
+</rss>`,
+			want: models.ParsedFeed{
+				Format:      models.FormatRSS2,
+				Title:       "Example & Co",
+				Link:        "https://example.com/",
+				Description: "News",
+				Language:    "en",
+				HubURL:      "https://hub.example.com/",
+				SelfURL:     "https://example.com/feed.xml",
+				Items: []models.ParsedItem{
+					{
+						Title:       "First post",
+						Link:        "https://example.com/first",
+						GUID:        "first",
+						Description: "<p>Hello</p>",
+						Author:      "Ann",
+						Categories:  []string{"go"},
+						Published:   time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC),
+					},
+					{
+						Title:    "Relative",
+						Link:     "https://example.com/second",
+						GUID:     "https://example.com/guid",
+						Warnings: []string{"missing publication date", "relative link '/second' resolved to https://example.com/second"},
+					},
+				},
+			},
+		},
+		{
+			name: "rss 1.0",
+			body: `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>RDF site</title>
+    <link>https://rdf.example.com/</link>
+    <description>Old school</description>
+  </channel>
+  <item>
+    <title>Item</title>
+    <link>https://rdf.example.com/item</link>
+    <dc:date>2024-03-01T10:00:00Z</dc:date>
+    <dc:subject>history</dc:subject>
+  </item>
+</rdf:RDF>`,
+			want: models.ParsedFeed{
+				Format:      models.FormatRSS1,
+				Title:       "RDF site",
+				Link:        "https://rdf.example.com/",
+				Description: "Old school",
+				Items: []models.ParsedItem{{
+					Title:      "Item",
+					Link:       "https://rdf.example.com/item",
+					Categories: []string{"history"},
+					Published:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+				}},
+			},
+		},
+		{
+			name: "atom",
+			body: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="html">Atom &lt;b&gt;blog&lt;/b&gt;</title>
+  <link href="https://atom.example.com/"/>
+  <link rel="self" href="https://atom.example.com/atom.xml"/>
+  <entry>
+    <title>Entry</title>
+    <id>urn:uuid:1</id>
+    <link rel="alternate" href="posts/1"/>
+    <summary>Short</summary>
+    <content type="html">&lt;p&gt;Long&lt;/p&gt;</content>
+    <author><name>Bo</name></author>
+    <category term="web"/>
+    <updated>2024-05-06T07:08:09+02:00</updated>
+  </entry>
+</feed>`,
+			want: models.ParsedFeed{
+				Format:  models.FormatAtom,
+				Title:   "Atom blog",
+				Link:    "https://atom.example.com/",
+				SelfURL: "https://atom.example.com/atom.xml",
+				Items: []models.ParsedItem{{
+					Title:       "Entry",
+					Link:        "https://atom.example.com/posts/1",
+					GUID:        "urn:uuid:1",
+					Description: "Short",
+					Content:     "<p>Long</p>",
+					Author:      "Bo",
+					Categories:  []string{"web"},
+					Published:   time.Date(2024, 5, 6, 5, 8, 9, 0, time.UTC),
+					Warnings:    []string{"relative link 'posts/1' resolved to https://atom.example.com/posts/1"},
+				}},
+			},
+		},
+		{
+			name: "json feed",
+			body: `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON",
+  "home_page_url": "https://json.example.com/",
+  "feed_url": "https://json.example.com/feed.json",
+  "hubs": [{"type": "WebSub", "url": "https://hub.example.com/"}],
+  "items": [{
+    "id": 42,
+    "external_url": "https://elsewhere.example.com/a",
+    "content_text": "a < b",
+    "tags": ["x", " y "],
+    "authors": [{"name": "Cy"}],
+    "date_published": "2024-01-02T03:04:05Z"
+  }]
+}`,
+			want: models.ParsedFeed{
+				Format:  models.FormatJSONFeed,
+				Title:   "JSON",
+				Link:    "https://json.example.com/",
+				HubURL:  "https://hub.example.com/",
+				SelfURL: "https://json.example.com/feed.json",
+				Items: []models.ParsedItem{{
+					Link:       "https://elsewhere.example.com/a",
+					GUID:       "42",
+					Content:    "a &lt; b",
+					Author:     "Cy",
+					Categories: []string{"x", "y"},
+					Published:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+					Warnings:   []string{"missing url, using external_url"},
+				}},
+			},
+		},
+		{
+			name:    "relative links resolve against the base url without a feed link",
+			baseURL: "https://base.example.com/blog/feed.xml",
+			body: `<rss version="2.0"><channel><title>T</title>
+<item><title>A</title><link>a.html</link><pubDate>2024-01-02</pubDate></item>
+</channel></rss>`,
+			want: models.ParsedFeed{
+				Format:   models.FormatRSS2,
+				Title:    "T",
+				Warnings: []string{"feed has no website link"},
+				Items: []models.ParsedItem{{
+					Title:     "A",
+					Link:      "https://base.example.com/blog/a.html",
+					Published: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+					Warnings:  []string{"relative link 'a.html' resolved to https://base.example.com/blog/a.html"},
+				}},
+			},
+		},
+		{
+			name: "windows-1252",
+			body: "<?xml version=\"1.0\" encoding=\"windows-1252\"?>\n" +
+				"<rss version=\"2.0\"><channel><title>Caf\xe9 \x93news\x94</title><link>https://cp.example.com/</link></channel></rss>",
+			want: models.ParsedFeed{
+				Format: models.FormatRSS2,
+				Title:  "Café “news”",
+				Link:   "https://cp.example.com/",
+			},
+		},
+		{name: "empty", body: "  \n", wantErr: true},
+		{name: "html page", body: "<html><body>hi</body></html>", wantErr: true},
+		{name: "unknown json feed version", body: `{"version": "2", "items": []}`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseFeed([]byte(test.body), test.baseURL)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseFeed() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, test.want) {
+				t.Errorf("parseFeed() =\n%+v\nwant\n%+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "Mon, 02 Jan 2006 15:04:05 -0700", want: time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{value: "Mon, 2 Jan 2006 15:04 +0100", want: time.Date(2006, 1, 2, 14, 4, 0, 0, time.UTC)},
+		{value: " 2006-01-02T15:04:05Z ", want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{value: "2006-01-02T15:04:05.123+02:00", want: time.Date(2006, 1, 2, 13, 4, 5, 123000000, time.UTC)},
+		{value: "2006-01-02 15:04:05", want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{value: "2006-01-02", want: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{value: "yesterday", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := parseDate(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseDate(%q) = %v, want an error", test.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.want) || got.Location() != time.UTC {
+				t.Errorf("parseDate(%q) = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"github.com/maevlava/Gator/internal/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter spreads requests to the same host over time, however feeds are scheduled
+type hostLimiter struct {
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+type hostSlot struct {
+	inFlight chan struct{} // buffered to the host's max concurrency
+	next     time.Time     // earliest time the next request may start
+}
+
+// hostLimits is shared by every fetch in the process
+var hostLimits = &hostLimiter{hosts: make(map[string]*hostSlot)}
+
+// acquire blocks until a request to host may start under cfg's limits.
+// The returned release func must be called once the response has been read.
+func (l *hostLimiter) acquire(ctx context.Context, cfg *config.Config, host string) (func(), error) {
+	host = strings.ToLower(host)
+	limit, interval, err := cfg.HostLimitFor(host)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{inFlight: make(chan struct{}, limit.MaxConcurrent)}
+		l.hosts[host] = slot
+	}
+	l.mu.Unlock()
+
+	select {
+	case slot.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-slot.inFlight }
+
+	// reserve a start time so concurrent callers queue up behind each other
+	l.mu.Lock()
+	start := time.Now()
+	if slot.next.After(start) {
+		start = slot.next
+	}
+	slot.next = start.Add(interval)
+	l.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"time"
+)
+
+// ReadHandler marks posts as read for the current user, along with their copies in other feeds
+func ReadHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: at least one post handle is required")
+	}
+
+	for _, arg := range cmd.Args {
+		post, err := findPost(ctx, state, arg)
+		if err != nil {
+			return err
+		}
+		_, err = state.DB.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: user.ID,
+			ReadAt: time.Now().UTC(),
+			PostID: post.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark post '%s' read: %w", post.Title, err)
+		}
+		fmt.Printf("Read: [%d] %s\n", post.Handle, post.Title)
+	}
+	return nil
+}
+
+// UnreadHandler puts posts back into the current user's unread list
+func UnreadHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: at least one post handle is required")
+	}
+
+	for _, arg := range cmd.Args {
+		post, err := findPost(ctx, state, arg)
+		if err != nil {
+			return err
+		}
+		_, err = state.DB.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark post '%s' unread: %w", post.Title, err)
+		}
+		fmt.Printf("Unread: [%d] %s\n", post.Handle, post.Title)
+	}
+	return nil
+}
+
+// CatchupHandler marks every post the current user can see as read, optionally limited
+// to one feed or to posts published before a date
+func CatchupHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	fs := flag.NewFlagSet("catchup", flag.ContinueOnError)
+	feedFlag := fs.String("feed", "", "only mark posts from this feed url or name, or saved search")
+	beforeFlag := fs.String("before", "", "only mark posts published before this date")
+	if _, err := parseFlags(fs, cmd.Args); err != nil {
+		return fmt.Errorf("invalid catchup flags: %w", err)
+	}
+
+	params := database.MarkPostsReadForUserParams{
+		UserID: user.ID,
+		ReadAt: time.Now().UTC(),
+	}
+	if *feedFlag != "" {
+		var err error
+		params.FeedID, params.SavedSearchID, err = findPostSource(ctx, state, user, *feedFlag)
+		if err != nil {
+			return err
+		}
+	}
+	if *beforeFlag != "" {
+		before, err := parseDate(*beforeFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --before date: %w", err)
+		}
+		params.Before = sql.NullTime{Time: before, Valid: true}
+	}
+
+	marked, err := state.DB.MarkPostsReadForUser(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to mark posts read for user '%s': %w", user.Name, err)
+	}
+	fmt.Printf("Marked %d posts as read\n", marked)
+	return nil
+}
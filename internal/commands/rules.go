@@ -0,0 +1,328 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule kinds and actions
+const (
+	ruleKeyword  = "keyword"
+	ruleRegex    = "regex"
+	ruleAuthor   = "author"
+	ruleCategory = "category"
+
+	actionHide      = "hide"
+	actionMarkRead  = "mark-read"
+	actionHighlight = "highlight"
+)
+
+// ruleTarget is the part of a post rules look at, whichever query it came from
+type ruleTarget struct {
+	ID          uuid2.UUID
+	FeedID      uuid2.UUID
+	Title       string
+	Description string
+	Content     string
+	Author      string
+	Categories  []string
+}
+
+// ruleVerdict is what the rules matching a post ask for
+type ruleVerdict struct {
+	Hide      bool
+	MarkRead  bool
+	Highlight bool
+}
+
+type compiledRule struct {
+	rule    database.GetRulesForUserRow
+	pattern *regexp.Regexp
+}
+
+// userRules are a user's rules, ready to check posts against
+type userRules struct {
+	user  database.User
+	rules []compiledRule
+}
+
+// loadRules reads and compiles the rules of user
+func loadRules(ctx context.Context, state *config.State, user database.User) (*userRules, error) {
+	rows, err := state.DB.GetRulesForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rules for user '%s': %w", user.Name, err)
+	}
+
+	rules := &userRules{user: user}
+	for _, row := range rows {
+		pattern, err := compileRule(row.Kind, row.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", row.Handle, err)
+		}
+		rules.rules = append(rules.rules, compiledRule{rule: row, pattern: pattern})
+	}
+	return rules, nil
+}
+
+// compileRule checks a rule's kind and turns its pattern into a regexp.
+// Keywords and authors match whole words, categories whole values, all ignoring case.
+func compileRule(kind, pattern string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.New("pattern must not be empty")
+	}
+	switch kind {
+	case ruleKeyword, ruleAuthor:
+		return regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(strings.TrimSpace(pattern)) + `($|\W)`), nil
+	case ruleRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return re, nil
+	case ruleCategory:
+		return regexp.MustCompile(`(?i)^\s*` + regexp.QuoteMeta(strings.TrimSpace(pattern)) + `\s*$`), nil
+	}
+	return nil, fmt.Errorf("unknown rule kind '%s': must be keyword, regex, author or category", kind)
+}
+
+func (r compiledRule) matches(post ruleTarget) bool {
+	if r.rule.FeedID.Valid && r.rule.FeedID.UUID != post.FeedID {
+		return false
+	}
+	switch r.rule.Kind {
+	case ruleKeyword, ruleRegex:
+		text := post.Title + "\n" + htmlToText(post.Description) + "\n" + htmlToText(post.Content)
+		return r.pattern.MatchString(text)
+	case ruleAuthor:
+		return r.pattern.MatchString(post.Author)
+	case ruleCategory:
+		for _, category := range post.Categories {
+			if r.pattern.MatchString(category) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verdict combines the actions of every rule matching post
+func (r *userRules) verdict(post ruleTarget) ruleVerdict {
+	var verdict ruleVerdict
+	for _, rule := range r.rules {
+		if !rule.matches(post) {
+			continue
+		}
+		switch rule.rule.Action {
+		case actionHide:
+			verdict.Hide = true
+		case actionMarkRead:
+			verdict.MarkRead = true
+		case actionHighlight:
+			verdict.Highlight = true
+		}
+	}
+	return verdict
+}
+
+// apply checks post against the rules and marks it read when a rule says so.
+// Listings drop hidden posts, and read ones when showing unread posts only.
+func (r *userRules) apply(ctx context.Context, state *config.State, post ruleTarget, read bool) (ruleVerdict, error) {
+	verdict := r.verdict(post)
+	if verdict.MarkRead && !read {
+		_, err := state.DB.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: r.user.ID,
+			ReadAt: time.Now().UTC(),
+			PostID: post.ID,
+		})
+		if err != nil {
+			return verdict, fmt.Errorf("failed to mark post '%s' read: %w", post.Title, err)
+		}
+	}
+	return verdict, nil
+}
+
+// highlightMark is the marker listings put after a highlighted post's title
+func highlightMark(highlight bool) string {
+	if highlight {
+		return " [!]"
+	}
+	return ""
+}
+
+// RulesHandler manages the current user's rules:
+// rules add <kind> <pattern> [--action hide|mark-read|highlight] [--feed X], rules list, rules rm <rule>,
+// rules test <kind> <pattern> [--feed X] [--limit N]
+func RulesHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: rules add|list|rm|test is required")
+	}
+
+	switch cmd.Args[0] {
+	case "add":
+		fs := flag.NewFlagSet("rules add", flag.ContinueOnError)
+		action := fs.String("action", actionHide, "what to do with matching posts: hide, mark-read or highlight")
+		feedFlag := fs.String("feed", "", "only apply to posts from this feed url or name")
+		args, err := parseFlags(fs, cmd.Args[1:])
+		if err != nil {
+			return fmt.Errorf("invalid rules flags: %w", err)
+		}
+		if len(args) < 2 {
+			return errors.New("not enough arguments: rule kind and pattern are required")
+		}
+		kind, pattern := args[0], strings.Join(args[1:], " ")
+		if _, err := compileRule(kind, pattern); err != nil {
+			return err
+		}
+		switch *action {
+		case actionHide, actionMarkRead, actionHighlight:
+		default:
+			return fmt.Errorf("invalid action '%s': must be hide, mark-read or highlight", *action)
+		}
+
+		params := database.CreateRuleParams{
+			ID:        uuid2.New(),
+			CreatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			Kind:      kind,
+			Pattern:   pattern,
+			Action:    *action,
+		}
+		if *feedFlag != "" {
+			feed, err := findFeed(ctx, state, *feedFlag)
+			if err != nil {
+				return err
+			}
+			params.FeedID = uuid2.NullUUID{UUID: feed.ID, Valid: true}
+		}
+		rule, err := state.DB.CreateRule(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create rule: %w", err)
+		}
+		fmt.Printf("Added rule %d\n", rule.Handle)
+		return nil
+
+	case "list":
+		rules, err := state.DB.GetRulesForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get rules for user '%s': %w", user.Name, err)
+		}
+		if structuredOutput(state) {
+			rows := make([]ruleRow, 0, len(rules))
+			for _, rule := range rules {
+				rows = append(rows, ruleRow{
+					Handle:  rule.Handle,
+					Kind:    rule.Kind,
+					Pattern: rule.Pattern,
+					Action:  rule.Action,
+					Feed:    rule.FeedName,
+				})
+			}
+			return writeRows(state, rows)
+		}
+		if len(rules) == 0 {
+			fmt.Println("No rules")
+			return nil
+		}
+		for _, rule := range rules {
+			scope := "all feeds"
+			if rule.FeedID.Valid {
+				scope = rule.FeedName
+			}
+			fmt.Printf("%d. %s %q -> %s (%s)\n", rule.Handle, rule.Kind, rule.Pattern, rule.Action, scope)
+		}
+		return nil
+
+	case "rm":
+		if len(cmd.Args) < 2 {
+			return errors.New("not enough arguments: rule number is required")
+		}
+		handle, err := strconv.ParseInt(cmd.Args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rule number '%s'", cmd.Args[1])
+		}
+		removed, err := state.DB.DeleteRule(ctx, database.DeleteRuleParams{
+			UserID: user.ID,
+			Handle: handle,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete rule %d: %w", handle, err)
+		}
+		if removed == 0 {
+			return fmt.Errorf("no rule %d", handle)
+		}
+		fmt.Printf("Removed rule %d\n", handle)
+		return nil
+
+	case "test":
+		fs := flag.NewFlagSet("rules test", flag.ContinueOnError)
+		feedFlag := fs.String("feed", "", "only apply to posts from this feed url or name")
+		limit := fs.Int("limit", 50, "number of recent posts to try the rule on")
+		args, err := parseFlags(fs, cmd.Args[1:])
+		if err != nil {
+			return fmt.Errorf("invalid rules flags: %w", err)
+		}
+		if len(args) < 2 {
+			return errors.New("not enough arguments: rule kind and pattern are required")
+		}
+		if *limit <= 0 {
+			return errors.New("limit must be a positive integer")
+		}
+		pattern, err := compileRule(args[0], strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		rule := compiledRule{
+			rule:    database.GetRulesForUserRow{Kind: args[0]},
+			pattern: pattern,
+		}
+		if *feedFlag != "" {
+			feed, err := findFeed(ctx, state, *feedFlag)
+			if err != nil {
+				return err
+			}
+			rule.rule.FeedID = uuid2.NullUUID{UUID: feed.ID, Valid: true}
+		}
+
+		listing := postListing{
+			SortBy:                "published",
+			GetPostsForUserParams: database.GetPostsForUserParams{UserID: user.ID},
+		}
+		posts, err := listing.posts(ctx, state.DB, int32(*limit))
+		if err != nil {
+			return fmt.Errorf("failed to get posts for user '%s': %w", user.Name, err)
+		}
+		matched := 0
+		for _, post := range posts {
+			if rule.matches(browseTarget(post)) {
+				matched++
+				fmt.Printf("[%d] %s (%s)\n", post.Handle, post.Title, post.FeedName)
+			}
+		}
+		fmt.Printf("%d of the last %d posts match\n", matched, len(posts))
+		return nil
+	}
+
+	return fmt.Errorf("unknown rules command '%s': must be add, list, rm or test", cmd.Args[0])
+}
+
+// browseTarget is what rules see of a browse row
+func browseTarget(post database.GetPostsForUserRow) ruleTarget {
+	return ruleTarget{
+		ID:          post.ID,
+		FeedID:      post.FeedID,
+		Title:       post.Title,
+		Description: post.Description.String,
+		Content:     post.Content.String,
+		Author:      post.Author,
+		Categories:  post.Categories,
+	}
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/database"
+	"testing"
+)
+
+func TestCompileRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		pattern string
+		wantErr bool
+	}{
+		{name: "keyword", kind: ruleKeyword, pattern: "crypto"},
+		{name: "keyword with regex characters", kind: ruleKeyword, pattern: "c++ (draft)"},
+		{name: "regex", kind: ruleRegex, pattern: `(?i)^sponsored:`},
+		{name: "author", kind: ruleAuthor, pattern: "Jane Doe"},
+		{name: "category", kind: ruleCategory, pattern: "Politics"},
+		{name: "bad regex", kind: ruleRegex, pattern: "(unclosed", wantErr: true},
+		{name: "empty pattern", kind: ruleKeyword, pattern: "  ", wantErr: true},
+		{name: "unknown kind", kind: "feed", pattern: "x", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := compileRule(test.kind, test.pattern)
+			if (err != nil) != test.wantErr {
+				t.Errorf("compileRule(%q, %q) error = %v, want error %v", test.kind, test.pattern, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleVerdict(t *testing.T) {
+	feedA, feedB := uuid2.New(), uuid2.New()
+	post := ruleTarget{
+		FeedID:      feedA,
+		Title:       "Sponsored: the C++ (draft) standard",
+		Description: "<p>All about <b>cryptography</b> &amp; more</p>",
+		Content:     "<p>Written for the crypto crowd.</p>",
+		Author:      "Jane Doe",
+		Categories:  []string{"Tech", " Politics "},
+	}
+	type rule struct {
+		kind, pattern, action string
+		feed                  uuid2.UUID
+	}
+	tests := []struct {
+		name  string
+		rules []rule
+		want  ruleVerdict
+	}{
+		{name: "no rules"},
+		{name: "keyword in content", rules: []rule{{kind: ruleKeyword, pattern: "crypto", action: actionHide}},
+			want: ruleVerdict{Hide: true}},
+		{name: "keyword ignores case", rules: []rule{{kind: ruleKeyword, pattern: "WRITTEN", action: actionHighlight}},
+			want: ruleVerdict{Highlight: true}},
+		{name: "keyword matches whole words only", rules: []rule{{kind: ruleKeyword, pattern: "crypt", action: actionHide}}},
+		{name: "keyword is literal", rules: []rule{{kind: ruleKeyword, pattern: "c++ (draft)", action: actionHide}},
+			want: ruleVerdict{Hide: true}},
+		{name: "keyword sees text, not markup", rules: []rule{{kind: ruleKeyword, pattern: "amp", action: actionHide}}},
+		{name: "regex", rules: []rule{{kind: ruleRegex, pattern: `^Sponsored:`, action: actionMarkRead}},
+			want: ruleVerdict{MarkRead: true}},
+		{name: "regex is case sensitive", rules: []rule{{kind: ruleRegex, pattern: `^sponsored:`, action: actionMarkRead}}},
+		{name: "author", rules: []rule{{kind: ruleAuthor, pattern: "jane doe", action: actionHide}},
+			want: ruleVerdict{Hide: true}},
+		{name: "author word", rules: []rule{{kind: ruleAuthor, pattern: "Doe", action: actionHide}},
+			want: ruleVerdict{Hide: true}},
+		{name: "author elsewhere in the post", rules: []rule{{kind: ruleAuthor, pattern: "crypto", action: actionHide}}},
+		{name: "category", rules: []rule{{kind: ruleCategory, pattern: "politics", action: actionHide}},
+			want: ruleVerdict{Hide: true}},
+		{name: "category matches whole values", rules: []rule{{kind: ruleCategory, pattern: "Tec", action: actionHide}}},
+		{name: "scoped to this feed", rules: []rule{{kind: ruleKeyword, pattern: "crypto", action: actionHide, feed: feedA}},
+			want: ruleVerdict{Hide: true}},
+		{name: "scoped to another feed", rules: []rule{{kind: ruleKeyword, pattern: "crypto", action: actionHide, feed: feedB}}},
+		{name: "actions combine", rules: []rule{
+			{kind: ruleKeyword, pattern: "crypto", action: actionMarkRead},
+			{kind: ruleAuthor, pattern: "Jane", action: actionHighlight},
+			{kind: ruleCategory, pattern: "Sports", action: actionHide},
+		}, want: ruleVerdict{MarkRead: true, Highlight: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules := &userRules{}
+			for _, r := range test.rules {
+				pattern, err := compileRule(r.kind, r.pattern)
+				if err != nil {
+					t.Fatal(err)
+				}
+				row := database.GetRulesForUserRow{Kind: r.kind, Pattern: r.pattern, Action: r.action}
+				if r.feed != uuid2.Nil {
+					row.FeedID = uuid2.NullUUID{UUID: r.feed, Valid: true}
+				}
+				rules.rules = append(rules.rules, compiledRule{rule: row, pattern: pattern})
+			}
+			if got := rules.verdict(post); got != test.want {
+				t.Errorf("verdict() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"time"
+)
+
+// StarHandler keeps posts for the current user; starred posts are never pruned
+func StarHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: at least one post handle is required")
+	}
+
+	for _, arg := range cmd.Args {
+		post, err := findPost(ctx, state, arg)
+		if err != nil {
+			return err
+		}
+		err = state.DB.StarPost(ctx, database.StarPostParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			StarredAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to star post '%s': %w", post.Title, err)
+		}
+		fmt.Printf("Starred: [%d] %s\n", post.Handle, post.Title)
+	}
+	return nil
+}
+
+// UnstarHandler removes posts from the current user's starred list
+func UnstarHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: at least one post handle is required")
+	}
+
+	for _, arg := range cmd.Args {
+		post, err := findPost(ctx, state, arg)
+		if err != nil {
+			return err
+		}
+		removed, err := state.DB.UnstarPost(ctx, database.UnstarPostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to unstar post '%s': %w", post.Title, err)
+		}
+		if removed == 0 {
+			fmt.Printf("Not starred: [%d] %s\n", post.Handle, post.Title)
+			continue
+		}
+		fmt.Printf("Unstarred: [%d] %s\n", post.Handle, post.Title)
+	}
+	return nil
+}
+
+// StarredHandler lists the current user's starred posts, most recently starred first
+func StarredHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	starred, err := state.DB.GetStarredPostsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get starred posts for user '%s': %w", user.Name, err)
+	}
+
+	rules, err := loadRules(ctx, state, user)
+	if err != nil {
+		return err
+	}
+	var posts []database.GetStarredPostsForUserRow
+	var marks []string
+	for _, post := range starred {
+		verdict, err := rules.apply(ctx, state, ruleTarget{
+			ID:          post.ID,
+			FeedID:      post.FeedID,
+			Title:       post.Title,
+			Description: post.Description.String,
+			Content:     post.Content.String,
+			Author:      post.Author,
+			Categories:  post.Categories,
+		}, post.Read)
+		if err != nil {
+			return err
+		}
+		if verdict.Hide {
+			continue
+		}
+		if verdict.MarkRead {
+			post.Read = true
+		}
+		posts = append(posts, post)
+		marks = append(marks, highlightMark(verdict.Highlight))
+	}
+	if structuredOutput(state) {
+		rows := make([]starredRow, 0, len(posts))
+		for i, post := range posts {
+			rows = append(rows, starredRow{
+				postRow: postRow{
+					Handle:      post.Handle,
+					Title:       post.Title,
+					URL:         post.Url,
+					Feed:        post.FeedName,
+					Author:      post.Author,
+					Categories:  listOf(post.Categories),
+					Published:   publishedTime(post.PublishedAt),
+					FirstSeen:   post.CreatedAt,
+					Description: htmlToText(post.Description.String),
+					Read:        post.Read,
+					Starred:     true,
+					Highlighted: marks[i] != "",
+					Article:     htmlToText(post.Article.String),
+				},
+				StarredAt: post.StarredAt,
+			})
+		}
+		return writeRows(state, rows)
+	}
+	if len(posts) == 0 {
+		fmt.Println("No starred posts")
+		return nil
+	}
+
+	for i, post := range posts {
+		fmt.Printf("[%d] %s (%s, starred %s)%s\n", post.Handle, post.Title, post.FeedName, post.StarredAt.Format(time.RFC1123), marks[i])
+		fmt.Printf("     %s\n", post.Url)
+	}
+	return nil
+}
+
+// LaterHandler manages the current user's read-later queue:
+// later add <handle...>, later next, later list, later done [handle...]
+func LaterHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("not enough arguments: later add|next|list|done is required")
+	}
+	args := cmd.Args[1:]
+
+	switch cmd.Args[0] {
+	case "add":
+		if len(args) < 1 {
+			return errors.New("not enough arguments: at least one post handle is required")
+		}
+		for _, arg := range args {
+			post, err := findPost(ctx, state, arg)
+			if err != nil {
+				return err
+			}
+			err = state.DB.AddLaterItem(ctx, database.AddLaterItemParams{
+				UserID:  user.ID,
+				PostID:  post.ID,
+				AddedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to queue post '%s': %w", post.Title, err)
+			}
+			fmt.Printf("Queued: [%d] %s\n", post.Handle, post.Title)
+		}
+		return nil
+
+	case "next", "list":
+		queued, err := state.DB.GetLaterItemsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get read-later queue for user '%s': %w", user.Name, err)
+		}
+
+		rules, err := loadRules(ctx, state, user)
+		if err != nil {
+			return err
+		}
+		var items []database.GetLaterItemsForUserRow
+		var marks []string
+		for _, item := range queued {
+			verdict, err := rules.apply(ctx, state, ruleTarget{
+				ID:          item.ID,
+				FeedID:      item.FeedID,
+				Title:       item.Title,
+				Description: item.Description.String,
+				Content:     item.Content.String,
+				Author:      item.Author,
+				Categories:  item.Categories,
+			}, item.Read)
+			if err != nil {
+				return err
+			}
+			if verdict.Hide {
+				continue
+			}
+			if verdict.MarkRead {
+				item.Read = true
+			}
+			items = append(items, item)
+			marks = append(marks, highlightMark(verdict.Highlight))
+		}
+		if structuredOutput(state) {
+			if cmd.Args[0] == "next" && len(items) > 1 {
+				items = items[:1]
+			}
+			rows := make([]laterRow, 0, len(items))
+			for i, item := range items {
+				rows = append(rows, laterRow{
+					postRow: postRow{
+						Handle:      item.Handle,
+						Title:       item.Title,
+						URL:         item.Url,
+						Feed:        item.FeedName,
+						Author:      item.Author,
+						Categories:  listOf(item.Categories),
+						Published:   publishedTime(item.PublishedAt),
+						FirstSeen:   item.CreatedAt,
+						Description: htmlToText(item.Description.String),
+						Read:        item.Read,
+						Starred:     item.Starred,
+						Highlighted: marks[i] != "",
+						Article:     htmlToText(item.Article.String),
+					},
+					Position: i + 1,
+					AddedAt:  item.AddedAt,
+				})
+			}
+			return writeRows(state, rows)
+		}
+		if len(items) == 0 {
+			fmt.Println("Read-later queue is empty")
+			return nil
+		}
+
+		if cmd.Args[0] == "next" {
+			item := items[0]
+			fmt.Printf("[%d] Title: %s%s\n", item.Handle, item.Title, marks[0])
+			fmt.Printf("Feed: %s\n", item.FeedName)
+			fmt.Printf("Queued: %s\n", item.AddedAt.Format(time.RFC1123))
+			fmt.Printf("Description: %s\n", postText(item.Description, item.Content))
+			if excerpt := articleExcerpt(item.Article); excerpt != "" {
+				fmt.Printf("Article: %s\n", excerpt)
+			}
+			fmt.Printf("URL: %s\n", item.Url)
+			fmt.Printf("%d more in the queue\n", len(items)-1)
+			return nil
+		}
+		for i, item := range items {
+			fmt.Printf("%d. [%d] %s (%s)%s\n", i+1, item.Handle, item.Title, item.FeedName, marks[i])
+		}
+		return nil
+
+	case "done":
+		// without arguments the post at the head of the queue is done
+		if len(args) == 0 {
+			items, err := state.DB.GetLaterItemsForUser(ctx, user.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get read-later queue for user '%s': %w", user.Name, err)
+			}
+			if len(items) == 0 {
+				fmt.Println("Read-later queue is empty")
+				return nil
+			}
+			args = []string{fmt.Sprint(items[0].Handle)}
+		}
+		for _, arg := range args {
+			post, err := findPost(ctx, state, arg)
+			if err != nil {
+				return err
+			}
+			removed, err := state.DB.RemoveLaterItem(ctx, database.RemoveLaterItemParams{
+				UserID: user.ID,
+				PostID: post.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to remove post '%s' from the queue: %w", post.Title, err)
+			}
+			if removed == 0 {
+				fmt.Printf("Not queued: [%d] %s\n", post.Handle, post.Title)
+				continue
+			}
+			fmt.Printf("Done: [%d] %s\n", post.Handle, post.Title)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown later command '%s': must be add, next, list or done", cmd.Args[0])
+}
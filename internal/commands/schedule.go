@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"log"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Digest schedule frequencies
+const (
+	digestDaily  = "daily"
+	digestWeekly = "weekly"
+)
+
+// scheduledDigestMaxPosts bounds the posts a mailed digest includes
+const scheduledDigestMaxPosts = 200
+
+// digestRetryDelay is how long a digest that failed to send waits before the next attempt
+const digestRetryDelay = 30 * time.Minute
+
+// digestScheduleCommand manages the current user's digest schedules:
+// digest schedule add daily|weekly --email ADDR [--at 07:00] [--on mon] [--by feed|category] [--mark-read],
+// digest schedule list, digest schedule rm <schedule>
+func digestScheduleCommand(ctx context.Context, state *config.State, args []string, user database.User) error {
+	if len(args) < 1 {
+		return errors.New("not enough arguments: digest schedule add|list|rm is required")
+	}
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("digest schedule add", flag.ContinueOnError)
+		email := fs.String("email", "", "address to mail the digest to")
+		at := fs.String("at", "07:00", "local time of day to send at, as HH:MM")
+		on := fs.String("on", "mon", "day of the week weekly digests are sent on")
+		by := fs.String("by", "feed", "group posts by feed or category")
+		markRead := fs.Bool("mark-read", false, "mark the mailed posts read")
+		rest, err := parseFlags(fs, args[1:])
+		if err != nil {
+			return fmt.Errorf("invalid digest schedule flags: %w", err)
+		}
+		if len(rest) < 1 || *email == "" {
+			return errors.New("not enough arguments: daily or weekly and --email are required")
+		}
+		frequency := rest[0]
+		if frequency != digestDaily && frequency != digestWeekly {
+			return fmt.Errorf("invalid frequency '%s': must be daily or weekly", frequency)
+		}
+		address, err := mail.ParseAddress(*email)
+		if err != nil {
+			return fmt.Errorf("invalid email '%s': %w", *email, err)
+		}
+		if *by != "feed" && *by != "category" {
+			return fmt.Errorf("invalid --by '%s': must be feed or category", *by)
+		}
+		weekday, err := parseWeekday(*on)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		nextRun, err := nextDigestRun(frequency, *at, weekday, now)
+		if err != nil {
+			return err
+		}
+		schedule, err := state.DB.CreateDigestSchedule(ctx, database.CreateDigestScheduleParams{
+			ID:        uuid2.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    user.ID,
+			Email:     address.Address,
+			Frequency: frequency,
+			SendAt:    *at,
+			Weekday:   int32(weekday),
+			GroupBy:   *by,
+			MarkRead:  *markRead,
+			NextRunAt: nextRun,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create digest schedule: %w", err)
+		}
+		fmt.Printf("Added digest schedule %d: %s to %s, next %s\n", schedule.Handle, describeSchedule(schedule), schedule.Email, schedule.NextRunAt.Local().Format(time.RFC1123))
+		return nil
+
+	case "list":
+		schedules, err := state.DB.GetDigestSchedulesForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get digest schedules for user '%s': %w", user.Name, err)
+		}
+		if structuredOutput(state) {
+			rows := make([]scheduleRow, 0, len(schedules))
+			for _, schedule := range schedules {
+				row := scheduleRow{
+					Handle:    schedule.Handle,
+					Frequency: schedule.Frequency,
+					At:        schedule.SendAt,
+					Email:     schedule.Email,
+					By:        schedule.GroupBy,
+					MarkRead:  schedule.MarkRead,
+					NextRun:   schedule.NextRunAt,
+				}
+				if schedule.Frequency == digestWeekly {
+					row.Weekday = strings.ToLower(time.Weekday(schedule.Weekday).String())
+				}
+				if schedule.LastSentAt.Valid {
+					row.LastSent = &schedule.LastSentAt.Time
+				}
+				rows = append(rows, row)
+			}
+			return writeRows(state, rows)
+		}
+		if len(schedules) == 0 {
+			fmt.Println("No digest schedules")
+			return nil
+		}
+		for _, schedule := range schedules {
+			lastSent := "never"
+			if schedule.LastSentAt.Valid {
+				lastSent = schedule.LastSentAt.Time.Local().Format(time.RFC1123)
+			}
+			fmt.Printf("%d. %s to %s, by %s, next %s, last sent %s\n", schedule.Handle, describeSchedule(schedule), schedule.Email,
+				schedule.GroupBy, schedule.NextRunAt.Local().Format(time.RFC1123), lastSent)
+		}
+		return nil
+
+	case "rm":
+		if len(args) < 2 {
+			return errors.New("not enough arguments: schedule number is required")
+		}
+		handle, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid schedule number '%s'", args[1])
+		}
+		removed, err := state.DB.DeleteDigestSchedule(ctx, database.DeleteDigestScheduleParams{
+			UserID: user.ID,
+			Handle: handle,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete digest schedule %d: %w", handle, err)
+		}
+		if removed == 0 {
+			return fmt.Errorf("no digest schedule %d", handle)
+		}
+		fmt.Printf("Removed digest schedule %d\n", handle)
+		return nil
+	}
+
+	return fmt.Errorf("unknown digest schedule command '%s': must be add, list or rm", args[0])
+}
+
+// digestSendCommand mails the current user's scheduled digests now, or with --dry-run prints them:
+// digest send [schedule] [--dry-run]
+func digestSendCommand(ctx context.Context, state *config.State, args []string, user database.User) error {
+	fs := flag.NewFlagSet("digest send", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the mail instead of sending it, changing nothing")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return fmt.Errorf("invalid digest send flags: %w", err)
+	}
+
+	schedules, err := state.DB.GetDigestSchedulesForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get digest schedules for user '%s': %w", user.Name, err)
+	}
+	if len(rest) > 0 {
+		handle, err := strconv.ParseInt(rest[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid schedule number '%s'", rest[0])
+		}
+		var matched []database.DigestSchedule
+		for _, schedule := range schedules {
+			if schedule.Handle == handle {
+				matched = append(matched, schedule)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("no digest schedule %d", handle)
+		}
+		schedules = matched
+	}
+	if len(schedules) == 0 {
+		return errors.New("no digest schedules: add one with digest schedule add")
+	}
+
+	for _, schedule := range schedules {
+		if *dryRun {
+			d, err := buildDigest(ctx, state, user, scheduleWindow(schedule, time.Now().UTC()), schedule.GroupBy, scheduledDigestMaxPosts)
+			if err != nil {
+				return err
+			}
+			// a preview works before mail is set up
+			from, err := smtpFrom(state.Config)
+			if err != nil {
+				from = "gator@localhost"
+			}
+			msg, err := digestMessage(from, schedule.Email, d)
+			if err != nil {
+				return fmt.Errorf("failed to build digest mail: %w", err)
+			}
+			os.Stdout.Write(msg)
+			fmt.Println()
+			continue
+		}
+
+		// sending early leaves the schedule's next run as it was
+		sent, err := sendScheduledDigest(ctx, state, schedule, user, schedule.NextRunAt)
+		if err != nil {
+			return err
+		}
+		if sent == 0 {
+			fmt.Printf("Nothing to send to %s: no unread posts\n", schedule.Email)
+			continue
+		}
+		fmt.Printf("Sent digest of %d posts to %s\n", sent, schedule.Email)
+	}
+	return nil
+}
+
+// sendDueDigests mails every digest whose time has come. A failed digest is retried after digestRetryDelay.
+func sendDueDigests(ctx context.Context, state *config.State) error {
+	now := time.Now().UTC()
+	schedules, err := state.DB.GetDueDigestSchedules(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to get due digest schedules: %w", err)
+	}
+
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			return nil
+		}
+		user, err := state.DB.GetUserByID(ctx, schedule.UserID)
+		if err != nil {
+			log.Printf("Failed to get user of digest schedule %d: %v", schedule.Handle, err)
+			continue
+		}
+		nextRun, err := nextDigestRun(schedule.Frequency, schedule.SendAt, time.Weekday(schedule.Weekday), now)
+		if err != nil {
+			log.Printf("Digest schedule %d of '%s': %v", schedule.Handle, user.Name, err)
+			continue
+		}
+
+		sent, err := sendScheduledDigest(ctx, state, schedule, user, nextRun)
+		if err != nil {
+			log.Printf("Failed to send digest to %s for '%s', retrying in %s: %v", schedule.Email, user.Name, digestRetryDelay, err)
+			err = state.DB.MarkDigestScheduleRun(ctx, database.MarkDigestScheduleRunParams{
+				ID:         schedule.ID,
+				LastSentAt: schedule.LastSentAt,
+				NextRunAt:  now.Add(digestRetryDelay),
+				UpdatedAt:  now,
+			})
+			if err != nil {
+				log.Printf("Failed to reschedule digest %d: %v", schedule.Handle, err)
+			}
+			continue
+		}
+		if sent > 0 {
+			log.Printf("Sent digest of %d posts to %s for '%s'", sent, schedule.Email, user.Name)
+		}
+	}
+	return nil
+}
+
+// sendScheduledDigest mails schedule's digest and moves the schedule on to nextRun, returning the
+// number of posts sent. Nothing is mailed when there are no unread posts; the next digest then
+// still covers everything since the last one sent.
+func sendScheduledDigest(ctx context.Context, state *config.State, schedule database.DigestSchedule, user database.User, nextRun time.Time) (int, error) {
+	now := time.Now().UTC()
+	d, err := buildDigest(ctx, state, user, scheduleWindow(schedule, now), schedule.GroupBy, scheduledDigestMaxPosts)
+	if err != nil {
+		return 0, err
+	}
+
+	lastSent := schedule.LastSentAt
+	if d.Total > 0 {
+		from, err := smtpFrom(state.Config)
+		if err != nil {
+			return 0, err
+		}
+		msg, err := digestMessage(from, schedule.Email, d)
+		if err != nil {
+			return 0, fmt.Errorf("failed to build digest mail: %w", err)
+		}
+		if err := sendMail(ctx, state.Config, schedule.Email, msg); err != nil {
+			return 0, err
+		}
+		// the mail is out, so a failure from here on must not get the digest sent again
+		if schedule.MarkRead {
+			if err := markDigestRead(ctx, state, user, d); err != nil {
+				log.Printf("%v", err)
+			}
+		}
+		lastSent = sql.NullTime{Time: now, Valid: true}
+	}
+
+	err = state.DB.MarkDigestScheduleRun(ctx, database.MarkDigestScheduleRunParams{
+		ID:         schedule.ID,
+		LastSentAt: lastSent,
+		NextRunAt:  nextRun,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		log.Printf("Failed to update digest schedule %d: %v", schedule.Handle, err)
+	}
+	return d.Total, nil
+}
+
+// scheduleWindow is the posts a scheduled digest covers: those first seen since the last one sent,
+// or one period back, up to now. Windowing on first-seen time rather than publication keeps late
+// arrivals with old dates from being skipped, and now becomes the next digest's start.
+func scheduleWindow(schedule database.DigestSchedule, now time.Time) digestWindow {
+	window := digestWindow{Since: now.AddDate(0, 0, -1), Until: now, FirstSeen: true}
+	if schedule.LastSentAt.Valid {
+		window.Since = schedule.LastSentAt.Time
+	} else if schedule.Frequency == digestWeekly {
+		window.Since = now.AddDate(0, 0, -7)
+	}
+	return window
+}
+
+// nextDigestRun is the first time after after that a schedule sends, at the local time of day
+// sendAt, on weekday for weekly schedules
+func nextDigestRun(frequency, sendAt string, weekday time.Weekday, after time.Time) (time.Time, error) {
+	clock, err := time.Parse("15:04", sendAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time of day '%s': use HH:MM", sendAt)
+	}
+	local := after.Local()
+	next := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+	for !next.After(after) || (frequency == digestWeekly && next.Weekday() != weekday) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next.UTC(), nil
+}
+
+// parseWeekday reads a day name such as mon or Monday
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(name)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || (len(name) >= 3 && strings.HasPrefix(full, name)) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day '%s': use a weekday like mon", name)
+}
+
+// describeSchedule says when a schedule sends, like "daily at 07:00" or "weekly on Monday at 07:00"
+func describeSchedule(schedule database.DigestSchedule) string {
+	if schedule.Frequency == digestWeekly {
+		return fmt.Sprintf("weekly on %s at %s", time.Weekday(schedule.Weekday), schedule.SendAt)
+	}
+	return fmt.Sprintf("daily at %s", schedule.SendAt)
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"database/sql"
+	"github.com/maevlava/Gator/internal/database"
+	"testing"
+	"time"
+)
+
+func TestNextDigestRun(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+	defer func() { time.Local = local }()
+
+	// Wednesday 2026-10-14 09:00 local
+	wednesday := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		name      string
+		frequency string
+		at        string
+		weekday   time.Weekday
+		after     time.Time
+		want      time.Time
+		wantErr   bool
+	}{
+		{name: "daily later today", frequency: digestDaily, at: "17:30", after: wednesday,
+			want: time.Date(2026, 10, 14, 17, 30, 0, 0, time.Local)},
+		{name: "daily already passed", frequency: digestDaily, at: "07:00", after: wednesday,
+			want: time.Date(2026, 10, 15, 7, 0, 0, 0, time.Local)},
+		{name: "daily exactly now is next day", frequency: digestDaily, at: "09:00", after: wednesday,
+			want: time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)},
+		{name: "weekly later this week", frequency: digestWeekly, at: "17:30", weekday: time.Friday, after: wednesday,
+			want: time.Date(2026, 10, 16, 17, 30, 0, 0, time.Local)},
+		{name: "weekly same day later", frequency: digestWeekly, at: "10:00", weekday: time.Wednesday, after: wednesday,
+			want: time.Date(2026, 10, 14, 10, 0, 0, 0, time.Local)},
+		{name: "weekly same day passed", frequency: digestWeekly, at: "08:00", weekday: time.Wednesday, after: wednesday,
+			want: time.Date(2026, 10, 21, 8, 0, 0, 0, time.Local)},
+		{name: "weekly across month end", frequency: digestWeekly, at: "06:00", weekday: time.Monday,
+			after: time.Date(2026, 10, 30, 12, 0, 0, 0, time.Local),
+			want:  time.Date(2026, 11, 2, 6, 0, 0, 0, time.Local)},
+		{name: "after given in UTC", frequency: digestDaily, at: "01:00",
+			after: time.Date(2026, 10, 14, 22, 30, 0, 0, time.UTC),
+			want:  time.Date(2026, 10, 15, 1, 0, 0, 0, time.Local)},
+		{name: "bad time", frequency: digestDaily, at: "7am", after: wednesday, wantErr: true},
+		{name: "out of range time", frequency: digestDaily, at: "24:00", after: wednesday, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := nextDigestRun(test.frequency, test.at, test.weekday, test.after)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("nextDigestRun() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("nextDigestRun() = %v, want %v", got.In(time.Local), test.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("nextDigestRun() location = %v, want UTC", got.Location())
+			}
+		})
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    time.Weekday
+		wantErr bool
+	}{
+		{name: "mon", want: time.Monday},
+		{name: "Monday", want: time.Monday},
+		{name: "FRI", want: time.Friday},
+		{name: "thurs", want: time.Thursday},
+		{name: "sun", want: time.Sunday},
+		{name: "saturday", want: time.Saturday},
+		{name: "t", wantErr: true},
+		{name: "tu", wantErr: true},
+		{name: "mondays", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseWeekday(test.name)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseWeekday(%q) = %v, want an error", test.name, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("parseWeekday(%q) = %v, want %v", test.name, got, test.want)
+			}
+		})
+	}
+}
+
+func TestScheduleWindow(t *testing.T) {
+	now := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	lastSent := time.Date(2026, 10, 17, 7, 0, 2, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule database.DigestSchedule
+		want     time.Time
+	}{
+		{name: "daily never sent", schedule: database.DigestSchedule{Frequency: digestDaily}, want: now.AddDate(0, 0, -1)},
+		{name: "weekly never sent", schedule: database.DigestSchedule{Frequency: digestWeekly}, want: now.AddDate(0, 0, -7)},
+		{name: "since last sent", want: lastSent, schedule: database.DigestSchedule{
+			Frequency:  digestWeekly,
+			LastSentAt: sql.NullTime{Time: lastSent, Valid: true},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window := scheduleWindow(test.schedule, now)
+			if !window.Since.Equal(test.want) || !window.Until.Equal(now) || !window.FirstSeen {
+				t.Errorf("scheduleWindow() = %+v, want since %v until %v on first-seen time", window, test.want, now)
+			}
+		})
+	}
+}
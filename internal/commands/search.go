@@ -0,0 +1,358 @@
+package commands
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"github.com/maevlava/Gator/internal/models"
+	"html"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// searchConfigs maps language codes to the Postgres text search configuration that stems them
+var searchConfigs = map[string]string{
+	"ar": "arabic", "ca": "catalan", "da": "danish", "de": "german", "el": "greek",
+	"en": "english", "es": "spanish", "eu": "basque", "fi": "finnish", "fr": "french",
+	"ga": "irish", "hi": "hindi", "hu": "hungarian", "hy": "armenian", "id": "indonesian",
+	"it": "italian", "lt": "lithuanian", "ne": "nepali", "nl": "dutch", "no": "norwegian",
+	"nb": "norwegian", "nn": "norwegian", "pt": "portuguese", "ro": "romanian", "ru": "russian",
+	"sr": "serbian", "sv": "swedish", "ta": "tamil", "tr": "turkish", "yi": "yiddish",
+}
+
+// searchConfigFor resolves a language code such as en-GB, or a configuration name such as german,
+// to a text search configuration; anything unknown gets the unstemmed simple configuration.
+func searchConfigFor(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	code, _, _ := strings.Cut(strings.ReplaceAll(language, "_", "-"), "-")
+	if name, ok := searchConfigs[code]; ok {
+		return name
+	}
+	for _, name := range searchConfigs {
+		if language == name {
+			return name
+		}
+	}
+	return "simple"
+}
+
+// detectSearchConfig adopts the language a feed declares, for feeds still on the simple configuration
+func detectSearchConfig(ctx context.Context, state *config.State, feed database.Feed, parsedFeed *models.ParsedFeed) error {
+	searchConfig := searchConfigFor(parsedFeed.Language)
+	if feed.SearchConfig != "simple" || searchConfig == "simple" {
+		return nil
+	}
+	return setSearchConfig(ctx, state, feed, searchConfig)
+}
+
+// setSearchConfig changes the configuration a feed's posts are indexed with and reindexes them
+func setSearchConfig(ctx context.Context, state *config.State, feed database.Feed, searchConfig string) error {
+	err := state.DB.SetFeedSearchConfig(ctx, database.SetFeedSearchConfigParams{
+		SearchConfig: searchConfig,
+		UpdatedAt:    time.Now().UTC(),
+		ID:           feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set search language of feed '%s': %w", feed.Name, err)
+	}
+	if err := state.DB.ReindexPostsForFeed(ctx, feed.ID); err != nil {
+		return fmt.Errorf("failed to reindex posts of feed '%s': %w", feed.Name, err)
+	}
+	return nil
+}
+
+// websearchQuery adapts user input for websearch_to_tsquery, which already understands
+// "quoted phrases", OR and -term, by also accepting AND and NOT
+func websearchQuery(query string) string {
+	var words []string
+	negate := false
+	inPhrase := false
+	for _, word := range strings.Fields(query) {
+		if !inPhrase {
+			switch word {
+			case "AND":
+				continue
+			case "NOT":
+				negate = true
+				continue
+			}
+			if negate {
+				word = "-" + word
+				negate = false
+			}
+		}
+		if strings.Count(word, `"`)%2 == 1 {
+			inPhrase = !inPhrase
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// SearchHandler finds the current user's posts matching a full-text query, best matches first
+func SearchHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	feedFlag := fs.String("feed", "", "only search posts from this feed url or name")
+	sinceFlag := fs.String("since", "", "only search posts from this date, or this long ago like 24h or 7d")
+	limit := fs.Int("limit", 10, "number of results to show")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("invalid search flags: %w", err)
+	}
+	if len(args) < 1 {
+		return errors.New("not enough arguments: search query is required")
+	}
+	if *limit <= 0 {
+		return errors.New("limit must be a positive integer")
+	}
+
+	params := database.SearchPostsForUserParams{
+		Query:    websearchQuery(strings.Join(args, " ")),
+		UserID:   user.ID,
+		RowLimit: int32(*limit),
+	}
+	if *feedFlag != "" {
+		feed, err := findFeed(ctx, state, *feedFlag)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid2.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if *sinceFlag != "" {
+		since, err := parseTimeBound(*sinceFlag, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		params.Since = sql.NullTime{Time: since, Valid: true}
+	}
+
+	rules, err := loadRules(ctx, state, user)
+	if err != nil {
+		return err
+	}
+	results, highlighted, err := searchPosts(ctx, state, params, rules)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput(state) {
+		rows := make([]searchRow, 0, len(results))
+		for _, result := range results {
+			snippet := strings.NewReplacer("<mark>", "", "</mark>", "").Replace(result.Snippet)
+			rows = append(rows, searchRow{
+				postRow: postRow{
+					Handle:      result.Handle,
+					Title:       result.Title,
+					URL:         result.Url,
+					Feed:        result.FeedName,
+					Author:      result.Author,
+					Categories:  listOf(result.Categories),
+					Published:   publishedTime(result.PublishedAt),
+					FirstSeen:   result.CreatedAt,
+					Description: htmlToText(result.Description.String),
+					Read:        result.Read,
+					Starred:     result.Starred,
+					Highlighted: highlighted[result.ID],
+					Article:     htmlToText(result.Article.String),
+				},
+				Rank:    result.Rank,
+				Snippet: strings.Join(strings.Fields(html.UnescapeString(snippet)), " "),
+			})
+		}
+		return writeRows(state, rows)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matching posts")
+		return nil
+	}
+	highlight := []string{"*", "*"}
+	if isTerminal(os.Stdout) {
+		highlight = []string{"\x1b[1m", "\x1b[0m"}
+	}
+	for _, result := range results {
+		date := result.CreatedAt
+		if result.PublishedAt.Valid {
+			date = result.PublishedAt.Time
+		}
+		fmt.Printf("[%d] %s (%s, %s)%s\n", result.Handle, result.Title, result.FeedName, date.Format(time.DateOnly), highlightMark(highlighted[result.ID]))
+		snippet := strings.NewReplacer("<mark>", highlight[0], "</mark>", highlight[1]).Replace(result.Snippet)
+		snippet = strings.Join(strings.Fields(html.UnescapeString(snippet)), " ")
+		if snippet != "" {
+			fmt.Printf("     %s\n", snippet)
+		}
+		fmt.Printf("     %s\n", result.Url)
+	}
+	return nil
+}
+
+// searchPosts runs a search in every feed language and returns up to params.RowLimit of the posts
+// rules leave listed, best matches first, along with which of them rules highlight. Posts rules hide
+// don't count against the limit: while they crowd out the results, it searches again for more.
+func searchPosts(ctx context.Context, state *config.State, params database.SearchPostsForUserParams, rules *userRules) ([]database.SearchPostsForUserRow, map[uuid2.UUID]bool, error) {
+	// the query is stemmed once per language so each search can use the index
+	configs, err := state.DB.GetSearchConfigs(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get search languages: %w", err)
+	}
+	limit := int(params.RowLimit)
+	verdicts := make(map[uuid2.UUID]ruleVerdict)
+	for {
+		var results []database.SearchPostsForUserRow
+		exhausted := true
+		for _, searchConfig := range configs {
+			params.SearchConfig = searchConfig
+			rows, err := state.DB.SearchPostsForUser(ctx, params)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to search posts: %w", err)
+			}
+			if len(rows) == int(params.RowLimit) {
+				exhausted = false
+			}
+			results = append(results, rows...)
+		}
+		slices.SortStableFunc(results, func(a, b database.SearchPostsForUserRow) int {
+			return cmp.Compare(b.Rank, a.Rank)
+		})
+
+		// rules only run on results up to the limit, so posts past it aren't marked read unseen
+		var listed []database.SearchPostsForUserRow
+		highlighted := make(map[uuid2.UUID]bool)
+		for _, result := range results {
+			if len(listed) == limit {
+				break
+			}
+			verdict, ok := verdicts[result.ID]
+			if !ok {
+				verdict, err = rules.apply(ctx, state, ruleTarget{
+					ID:          result.ID,
+					FeedID:      result.FeedID,
+					Title:       result.Title,
+					Description: result.Description.String,
+					Content:     result.Content.String,
+					Author:      result.Author,
+					Categories:  result.Categories,
+				}, result.Read)
+				if err != nil {
+					return nil, nil, err
+				}
+				verdicts[result.ID] = verdict
+			}
+			if verdict.MarkRead {
+				result.Read = true
+			}
+			if verdict.Hide {
+				continue
+			}
+			highlighted[result.ID] = verdict.Highlight
+			listed = append(listed, result)
+		}
+		if len(listed) == limit || exhausted {
+			return listed, highlighted, nil
+		}
+		params.RowLimit *= 2
+	}
+}
+
+// SaveSearchHandler pins a search as a virtual feed of the current user's matching posts
+func SaveSearchHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	fs := flag.NewFlagSet("savesearch", flag.ContinueOnError)
+	name := fs.String("name", "", "name to browse the search by, like a feed")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return fmt.Errorf("invalid savesearch flags: %w", err)
+	}
+	if len(args) < 1 || *name == "" {
+		return errors.New("not enough arguments: search query and --name are required")
+	}
+
+	savedSearch, err := state.DB.CreateSavedSearch(ctx, database.CreateSavedSearchParams{
+		ID:        uuid2.New(),
+		CreatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Name:      *name,
+		Query:     websearchQuery(strings.Join(args, " ")),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save search '%s': %w", *name, err)
+	}
+
+	// posts already stored are matched once; new ones as they arrive
+	matched, err := state.DB.MatchPostsForSavedSearch(ctx, database.MatchPostsForSavedSearchParams{
+		MatchedAt:     time.Now().UTC(),
+		SavedSearchID: savedSearch.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to match posts for saved search '%s': %w", *name, err)
+	}
+
+	fmt.Printf("Saved search %s: %d matching posts\n", savedSearch.Name, matched)
+	return nil
+}
+
+// matchSavedSearches files a newly stored or newly shared post under every saved search it matches
+func matchSavedSearches(ctx context.Context, state *config.State, post database.Post, output scrapeOutput) {
+	err := state.DB.MatchSavedSearchesForPost(ctx, database.MatchSavedSearchesForPostParams{
+		MatchedAt: time.Now().UTC(),
+		PostID:    post.ID,
+	})
+	if err != nil {
+		output.logf("Failed to match saved searches for '%s': %v", post.Title, err)
+	}
+}
+
+// rematchSavedSearches files an edited post under the saved searches it matches now,
+// dropping it from those its old text matched
+func rematchSavedSearches(ctx context.Context, state *config.State, post database.Post, output scrapeOutput) {
+	if err := state.DB.DeleteSavedSearchMatchesForPost(ctx, post.ID); err != nil {
+		output.logf("Failed to clear saved search matches for '%s': %v", post.Title, err)
+		return
+	}
+	matchSavedSearches(ctx, state, post, output)
+}
+
+// matchSavedSearchesForUser files the posts user can now see under each of their saved searches,
+// after following a feed brings in posts stored before
+func matchSavedSearchesForUser(ctx context.Context, state *config.State, user database.User) error {
+	savedSearches, err := state.DB.GetSavedSearchesForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get saved searches for user '%s': %w", user.Name, err)
+	}
+	for _, savedSearch := range savedSearches {
+		_, err := state.DB.MatchPostsForSavedSearch(ctx, database.MatchPostsForSavedSearchParams{
+			MatchedAt:     time.Now().UTC(),
+			SavedSearchID: savedSearch.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to match posts for saved search '%s': %w", savedSearch.Name, err)
+		}
+	}
+	return nil
+}
+
+// findPostSource resolves a --feed argument to a saved search of user's or, failing that, a feed
+func findPostSource(ctx context.Context, state *config.State, user database.User, nameOrUrl string) (feedID, savedSearchID uuid2.NullUUID, err error) {
+	savedSearch, err := state.DB.GetSavedSearchByName(ctx, database.GetSavedSearchByNameParams{
+		UserID: user.ID,
+		Name:   nameOrUrl,
+	})
+	if err == nil {
+		return feedID, uuid2.NullUUID{UUID: savedSearch.ID, Valid: true}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return feedID, savedSearchID, fmt.Errorf("failed to get saved search '%s': %w", nameOrUrl, err)
+	}
+
+	feed, err := findFeed(ctx, state, nameOrUrl)
+	if err != nil {
+		return feedID, savedSearchID, err
+	}
+	return uuid2.NullUUID{UUID: feed.ID, Valid: true}, savedSearchID, nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import "testing"
+
+func TestWebsearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "go generics", want: "go generics"},
+		{query: "go AND generics", want: "go generics"},
+		{query: "go NOT rust", want: "go -rust"},
+		{query: "go OR rust -java", want: "go OR rust -java"},
+		{query: `"range over func" NOT iterators`, want: `"range over func" -iterators`},
+		{query: `"rust AND NOT go" zig`, want: `"rust AND NOT go" zig`},
+		{query: "  spaced   out  ", want: "spaced out"},
+		{query: "NOT", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			if got := websearchQuery(test.query); got != test.want {
+				t.Errorf("websearchQuery(%q) = %q, want %q", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSearchConfigFor(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{language: "en", want: "english"},
+		{language: "en-GB", want: "english"},
+		{language: "de_AT", want: "german"},
+		{language: " FR ", want: "french"},
+		{language: "nb-NO", want: "norwegian"},
+		{language: "german", want: "german"},
+		{language: "ja", want: "simple"},
+		{language: "klingon", want: "simple"},
+		{language: "", want: "simple"},
+	}
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			if got := searchConfigFor(test.language); got != test.want {
+				t.Errorf("searchConfigFor(%q) = %q, want %q", test.language, got, test.want)
+			}
+		})
+	}
+}
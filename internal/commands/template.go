@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"github.com/maevlava/Gator/internal/config"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateColors are the names the color template function accepts
+var templateColors = map[string]string{
+	"bold": "1", "dim": "2", "italic": "3", "underline": "4",
+	"black": "30", "red": "31", "green": "32", "yellow": "33",
+	"blue": "34", "magenta": "35", "cyan": "36", "white": "37",
+}
+
+// templateFuncs are the helpers available to --template and configured templates
+var templateFuncs = template.FuncMap{
+	"ago":      templateAgo,
+	"date":     templateDate,
+	"truncate": templateTruncate,
+	"wrap":     templateWrap,
+	"color":    templateColor,
+	"join":     func(sep string, values []string) string { return strings.Join(values, sep) },
+}
+
+// outputTemplate returns the template command should print each row with, or nil for its built-in text.
+// --template names a configured template or is the template itself; otherwise a configured template
+// named after the command applies.
+func outputTemplate(state *config.State, command string) (*template.Template, error) {
+	if structuredOutput(state) {
+		return nil, nil
+	}
+	text := state.Config.Template
+	if text == "" {
+		text = state.Config.Templates[command]
+	} else if named, ok := state.Config.Templates[text]; ok {
+		text = named
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(command).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderRow prints one row with tmpl, ending it with a newline unless the template does
+func renderRow(tmpl *template.Template, row any) error {
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, row); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	_, err := os.Stdout.Write(out.Bytes())
+	return err
+}
+
+// templateTime accepts the time types rows carry; ok is false for a missing time
+func templateTime(value any) (t time.Time, ok bool, err error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true, nil
+	case *time.Time:
+		if v == nil {
+			return t, false, nil
+		}
+		return *v, true, nil
+	case sql.NullTime:
+		return v.Time, v.Valid, nil
+	}
+	return t, false, fmt.Errorf("expected a time, got %T", value)
+}
+
+// templateAgo renders how long ago a time was, like 5m ago or 3d ago; empty for a missing time
+func templateAgo(value any) (string, error) {
+	t, ok, err := templateTime(value)
+	if err != nil || !ok {
+		return "", err
+	}
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now", nil
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes())), nil
+	case elapsed < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours())), nil
+	}
+	return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24)), nil
+}
+
+// templateDate formats a time in local time with a Go layout such as "2006-01-02"; empty for a missing time
+func templateDate(layout string, value any) (string, error) {
+	t, ok, err := templateTime(value)
+	if err != nil || !ok {
+		return "", err
+	}
+	return t.Local().Format(layout), nil
+}
+
+// templateTruncate shortens s to at most n characters, ending in … when cut
+func templateTruncate(n int, s string) (string, error) {
+	if n < 1 {
+		return "", fmt.Errorf("truncate length must be at least 1, got %d", n)
+	}
+	return truncate(s, n), nil
+}
+
+// templateWrap breaks s into lines of at most width characters
+func templateWrap(width int, s string) (string, error) {
+	if width < 1 {
+		return "", fmt.Errorf("wrap width must be at least 1, got %d", width)
+	}
+	return strings.Join(wrapText(s, width), "\n"), nil
+}
+
+// templateColor styles s with a color or style name, only when printing to a terminal and NO_COLOR is unset
+func templateColor(name, s string) (string, error) {
+	code, ok := templateColors[name]
+	if !ok {
+		return "", fmt.Errorf("unknown color '%s'", name)
+	}
+	if !isTerminal(os.Stdout) || os.Getenv("NO_COLOR") != "" {
+		return s, nil
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m", nil
+}
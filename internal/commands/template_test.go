@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestTemplateHelpers(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	now := time.Now()
+	published := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	row := browseRow{
+		postRow: postRow{
+			Title:       "A rather long title about Go",
+			Feed:        "Go Blog",
+			Categories:  []string{"go", "release"},
+			Published:   &published,
+			FirstSeen:   now.Add(-(3*time.Hour + 10*time.Minute)),
+			Description: "one two three four five six",
+		},
+	}
+	tests := []struct {
+		name     string
+		template string
+		data     any
+		want     string
+		wantErr  string
+	}{
+		{name: "truncate", template: `{{.Title | truncate 10}}`, data: row, want: "A rather …"},
+		{name: "truncate fits", template: `{{.Feed | truncate 7}}`, data: row, want: "Go Blog"},
+		{name: "truncate squeezes spaces", template: `{{"a   b" | truncate 5}}`, want: "a b"},
+		{name: "truncate to one", template: `{{.Title | truncate 1}}`, data: row, want: "…"},
+		{name: "truncate zero", template: `{{.Title | truncate 0}}`, data: row, wantErr: "truncate length must be at least 1"},
+		{name: "truncate negative", template: `{{.Title | truncate -3}}`, data: row, wantErr: "truncate length must be at least 1"},
+		{name: "wrap", template: `{{.Description | wrap 9}}`, data: row, want: "one two\nthree\nfour five\nsix"},
+		{name: "wrap long word", template: `{{"abcdefgh" | wrap 3}}`, want: "abc\ndef\ngh"},
+		{name: "wrap zero", template: `{{.Description | wrap 0}}`, data: row, wantErr: "wrap width must be at least 1"},
+		{name: "join", template: `{{.Categories | join ", "}}`, data: row, want: "go, release"},
+		{name: "join empty", template: `[{{.AlsoIn | join ", "}}]`, data: row, want: "[]"},
+		{name: "date", template: `{{.Published | date "2006-01-02 15:04"}}`, data: row, want: "2026-10-18 09:30"},
+		{name: "date missing", template: `[{{.Published | date "2006"}}]`, data: browseRow{}, want: "[]"},
+		{name: "date null time", template: `[{{. | date "2006"}}]`, data: sql.NullTime{}, want: "[]"},
+		{name: "date not a time", template: `{{.Title | date "2006"}}`, data: row, wantErr: "expected a time, got string"},
+		{name: "ago hours", template: `{{.FirstSeen | ago}}`, data: row, want: "3h ago"},
+		{name: "ago minutes", template: `{{. | ago}}`, data: now.Add(-(5*time.Minute + 10*time.Second)), want: "5m ago"},
+		{name: "ago days", template: `{{. | ago}}`, data: now.Add(-50 * time.Hour), want: "2d ago"},
+		{name: "ago just now", template: `{{. | ago}}`, data: now, want: "just now"},
+		{name: "ago missing", template: `[{{.Published | ago}}]`, data: browseRow{}, want: "[]"},
+		{name: "color off without a terminal", template: `{{.Feed | color "cyan"}}`, data: row, want: "Go Blog"},
+		{name: "unknown color", template: `{{.Feed | color "teal"}}`, data: row, wantErr: "unknown color 'teal'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl, err := template.New(test.name).Funcs(templateFuncs).Parse(test.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			err = tmpl.Execute(&out, test.data)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Execute() error = %v, want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.want {
+				t.Errorf("Execute() = %q, want %q", out.String(), test.want)
+			}
+		})
+	}
+}
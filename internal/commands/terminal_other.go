@@ -0,0 +1,26 @@
+//go:build !unix
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// the reader drives the terminal through stty and SIGWINCH, which only unix systems have
+var errTerminalUnsupported = fmt.Errorf("tui is not supported on %s", runtime.GOOS)
+
+func enterRawMode() (func(), error) {
+	return nil, errTerminalUnsupported
+}
+
+func suspendRawMode() (func(), error) {
+	return nil, errTerminalUnsupported
+}
+
+func terminalSize() (int, int, error) {
+	return 0, 0, errTerminalUnsupported
+}
+
+func notifyResize(c chan<- os.Signal) {}
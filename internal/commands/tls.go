@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"net/http"
+	"os"
+	"time"
+)
+
+// feedTLSConfig builds the TLS settings stored for a feed, or nil when the feed uses the system defaults.
+func feedTLSConfig(feed database.Feed) (*tls.Config, error) {
+	if !feed.TlsCaFile.Valid && !feed.TlsCertFile.Valid && !feed.TlsKeyFile.Valid && !feed.TlsServerName.Valid {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if feed.TlsCaFile.Valid {
+		caPEM, err := os.ReadFile(feed.TlsCaFile.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %s: %w", feed.TlsCaFile.String, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", feed.TlsCaFile.String)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if feed.TlsCertFile.Valid != feed.TlsKeyFile.Valid {
+		return nil, errors.New("client certificate and key must be set together")
+	}
+	if feed.TlsCertFile.Valid {
+		cert, err := tls.LoadX509KeyPair(feed.TlsCertFile.String, feed.TlsKeyFile.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %s: %w", feed.TlsCertFile.String, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if feed.TlsServerName.Valid {
+		tlsConfig.ServerName = feed.TlsServerName.String
+	}
+
+	return tlsConfig, nil
+}
+
+// newFeedClient returns the HTTP client used to fetch a feed, applying its TLS settings if any
+// and recording or replaying responses when asked to.
+func newFeedClient(cfg *config.Config, feed database.Feed) (*http.Client, error) {
+	client := &http.Client{Timeout: time.Second * 30}
+
+	if cfg.ReplayDir != "" {
+		client.Transport = &archiveTransport{dir: cfg.ReplayDir, replay: true}
+		return client, nil
+	}
+
+	var transport http.RoundTripper = http.DefaultTransport
+	tlsConfig, err := feedTLSConfig(feed)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		tlsTransport := http.DefaultTransport.(*http.Transport).Clone()
+		tlsTransport.TLSClientConfig = tlsConfig
+		transport = tlsTransport
+	}
+	if cfg.RecordDir != "" {
+		transport = &archiveTransport{dir: cfg.RecordDir, next: transport}
+	}
+	client.Transport = transport
+
+	return client, nil
+}
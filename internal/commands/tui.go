@@ -0,0 +1,778 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// tuiPageSize is how many posts the reader loads at a time
+const tuiPageSize = 100
+
+// Panes of the reader, in tab order
+const (
+	paneSources = iota
+	panePosts
+	paneArticle
+)
+
+// ANSI sequences used by the reader
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiUnderline = "\x1b[4m"
+	ansiReverse   = "\x1b[7m"
+	ansiYellow    = "\x1b[33m"
+)
+
+const tuiHelp = "j/k move  tab pane  enter open  r read  s star  / search  u unread/all  R refresh  o open link  q quit"
+
+// tuiSource is an entry of the feeds pane: every followed post, one feed or a saved search
+type tuiSource struct {
+	Label         string
+	Unread        int64
+	FeedID        uuid2.NullUUID
+	SavedSearchID uuid2.NullUUID
+}
+
+// tuiPost is a post as the reader lists and shows it
+type tuiPost struct {
+	ID        uuid2.UUID
+	Handle    int64
+	Title     string
+	FeedName  string
+	Url       string
+	Date      time.Time
+	Body      string
+	Read      bool
+	Starred   bool
+	Highlight bool
+}
+
+// tui is the state of a running reader
+type tui struct {
+	ctx   context.Context
+	state *config.State
+	user  database.User
+	rules *userRules
+	out   *bufio.Writer
+
+	width, height int
+	focus         int
+
+	sources              []tuiSource
+	sourceIdx, sourceTop int
+
+	posts            []tuiPost
+	postIdx, postTop int
+	listing          postListing
+	more             bool
+	searchQuery      string
+	unreadOnly       bool
+
+	articleTop int
+	status     string
+	prompt     *string
+}
+
+// TUIHandler runs a full-screen, keyboard-driven reader
+func TUIHandler(ctx context.Context, state *config.State, cmd CLI, user database.User) error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return errors.New("tui needs an interactive terminal")
+	}
+
+	rules, err := loadRules(ctx, state, user)
+	if err != nil {
+		return err
+	}
+	t := &tui{
+		ctx:        ctx,
+		state:      state,
+		user:       user,
+		rules:      rules,
+		out:        bufio.NewWriter(os.Stdout),
+		unreadOnly: true,
+		focus:      paneSources,
+	}
+	if err := t.resize(); err != nil {
+		return err
+	}
+	if err := t.loadSources(); err != nil {
+		return err
+	}
+	if err := t.loadPosts(); err != nil {
+		return err
+	}
+
+	restore, err := enterRawMode()
+	if err != nil {
+		return fmt.Errorf("failed to switch terminal to raw mode: %w", err)
+	}
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		t.out.WriteString("\x1b[?25h\x1b[?1049l")
+		t.out.Flush()
+		restore()
+	}()
+
+	// keys are read one at a time on request so that a browser started by openLink gets the input
+	keys := make(chan string)
+	wantKey := make(chan struct{}, 1)
+	go readKeys(wantKey, keys)
+	wantKey <- struct{}{}
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+
+	for {
+		t.draw()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resized:
+			if err := t.resize(); err != nil {
+				return err
+			}
+		case key, ok := <-keys:
+			if !ok || t.handleKey(key) {
+				return nil
+			}
+			wantKey <- struct{}{}
+		}
+	}
+}
+
+// readKeys turns raw terminal input into key names, one per request, closing keys when input ends
+func readKeys(want <-chan struct{}, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for range want {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		switch input := string(buf[:n]); input {
+		case "\x1b[A", "\x1bOA":
+			keys <- "up"
+		case "\x1b[B", "\x1bOB":
+			keys <- "down"
+		case "\x1b[C", "\x1bOC":
+			keys <- "right"
+		case "\x1b[D", "\x1bOD":
+			keys <- "left"
+		case "\x1b[5~":
+			keys <- "pgup"
+		case "\x1b[6~":
+			keys <- "pgdown"
+		case "\x1b":
+			keys <- "esc"
+		case "\r", "\n":
+			keys <- "enter"
+		case "\t":
+			keys <- "tab"
+		case "\x7f", "\b":
+			keys <- "backspace"
+		case "\x03":
+			keys <- "ctrl-c"
+		default:
+			keys <- input
+		}
+	}
+}
+
+func (t *tui) resize() error {
+	width, height, err := terminalSize()
+	if err != nil {
+		return err
+	}
+	t.width, t.height = width, height
+	return nil
+}
+
+// loadSources reads followed feeds and saved searches with their unread counts, keeping the selection
+func (t *tui) loadSources() error {
+	feeds, err := t.state.DB.GetFollowedFeedsForUser(t.ctx, t.user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get followed feeds for user '%s': %w", t.user.Name, err)
+	}
+	savedSearches, err := t.state.DB.GetSavedSearchesForUser(t.ctx, t.user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get saved searches for user '%s': %w", t.user.Name, err)
+	}
+
+	all := tuiSource{Label: "All"}
+	sources := []tuiSource{all}
+	for _, feed := range feeds {
+		sources[0].Unread += feed.Unread
+		sources = append(sources, tuiSource{
+			Label:  feed.Name,
+			Unread: feed.Unread,
+			FeedID: uuid2.NullUUID{UUID: feed.ID, Valid: true},
+		})
+	}
+	for _, savedSearch := range savedSearches {
+		sources = append(sources, tuiSource{
+			Label:         "? " + savedSearch.Name,
+			Unread:        savedSearch.Unread,
+			SavedSearchID: uuid2.NullUUID{UUID: savedSearch.ID, Valid: true},
+		})
+	}
+
+	t.sources = sources
+	t.sourceIdx = min(t.sourceIdx, len(sources)-1)
+	return nil
+}
+
+// loadPosts lists the selected source's posts from the start
+func (t *tui) loadPosts() error {
+	source := t.sources[t.sourceIdx]
+	t.searchQuery = ""
+	t.listing = postListing{
+		SortBy: "published",
+		GetPostsForUserParams: database.GetPostsForUserParams{
+			UserID:        t.user.ID,
+			UnreadOnly:    t.unreadOnly,
+			FeedID:        source.FeedID,
+			SavedSearchID: source.SavedSearchID,
+		},
+	}
+	t.posts = nil
+	t.postIdx, t.postTop, t.articleTop = 0, 0, 0
+	return t.loadMorePosts()
+}
+
+// loadMorePosts appends the next page of the current listing
+func (t *tui) loadMorePosts() error {
+	stories, boundary, more, err := browsePage(t.ctx, t.state, t.listing, tuiPageSize, false, t.rules)
+	if err != nil {
+		return fmt.Errorf("failed to get posts for user '%s': %w", t.user.Name, err)
+	}
+	for _, story := range stories {
+		post := story.Post
+		date := post.CreatedAt
+		if post.PublishedAt.Valid {
+			date = post.PublishedAt.Time
+		}
+		t.posts = append(t.posts, tuiPost{
+			ID:        post.ID,
+			Handle:    post.Handle,
+			Title:     post.Title,
+			FeedName:  post.FeedName,
+			Url:       post.Url,
+			Date:      date,
+			Body:      firstNonEmpty(post.Article.String, post.Content.String, post.Description.String),
+			Read:      post.Read,
+			Starred:   post.Starred,
+			Highlight: story.Highlight,
+		})
+	}
+	t.more = more
+	if more {
+		nextBrowsePage(&t.listing, boundary)
+	}
+	return nil
+}
+
+// search replaces the post list with the results of query
+func (t *tui) search(query string) error {
+	results, highlighted, err := searchPosts(t.ctx, t.state, database.SearchPostsForUserParams{
+		Query:    websearchQuery(query),
+		UserID:   t.user.ID,
+		RowLimit: tuiPageSize,
+	}, t.rules)
+	if err != nil {
+		return err
+	}
+
+	t.posts = nil
+	for _, result := range results {
+		date := result.CreatedAt
+		if result.PublishedAt.Valid {
+			date = result.PublishedAt.Time
+		}
+		t.posts = append(t.posts, tuiPost{
+			ID:        result.ID,
+			Handle:    result.Handle,
+			Title:     result.Title,
+			FeedName:  result.FeedName,
+			Url:       result.Url,
+			Date:      date,
+			Body:      firstNonEmpty(result.Article.String, result.Content.String, result.Description.String),
+			Read:      result.Read,
+			Starred:   result.Starred,
+			Highlight: highlighted[result.ID],
+		})
+	}
+	t.searchQuery = query
+	t.more = false
+	t.postIdx, t.postTop, t.articleTop = 0, 0, 0
+	t.focus = panePosts
+	t.status = fmt.Sprintf("%d results", len(t.posts))
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// handleKey acts on one key press and reports whether the reader should quit
+func (t *tui) handleKey(key string) bool {
+	if t.prompt != nil {
+		t.handlePromptKey(key)
+		return false
+	}
+
+	t.status = ""
+	var err error
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "tab", "l", "right":
+		t.focus = min(t.focus+1, paneArticle)
+	case "h", "left":
+		t.focus = max(t.focus-1, paneSources)
+	case "j", "down":
+		err = t.move(1)
+	case "k", "up":
+		err = t.move(-1)
+	case "pgdown", " ":
+		err = t.move(t.paneHeight())
+	case "pgup":
+		err = t.move(-t.paneHeight())
+	case "enter":
+		err = t.open()
+	case "esc":
+		if t.searchQuery != "" {
+			err = t.loadPosts()
+		}
+	case "r":
+		err = t.toggleRead()
+	case "s":
+		err = t.toggleStar()
+	case "u":
+		t.unreadOnly = !t.unreadOnly
+		err = t.loadPosts()
+		if t.unreadOnly {
+			t.status = "Showing unread posts"
+		} else {
+			t.status = "Showing all posts"
+		}
+	case "/":
+		query := ""
+		t.prompt = &query
+	case "R":
+		err = t.refresh()
+	case "o":
+		err = t.openLink()
+	}
+	if err != nil {
+		t.status = "Error: " + err.Error()
+	}
+	return false
+}
+
+func (t *tui) handlePromptKey(key string) {
+	switch key {
+	case "esc", "ctrl-c":
+		t.prompt = nil
+	case "enter":
+		query := strings.TrimSpace(*t.prompt)
+		t.prompt = nil
+		if query == "" {
+			return
+		}
+		if err := t.search(query); err != nil {
+			t.status = "Error: " + err.Error()
+		}
+	case "backspace":
+		if runes := []rune(*t.prompt); len(runes) > 0 {
+			*t.prompt = string(runes[:len(runes)-1])
+		}
+	case "up", "down", "left", "right", "pgup", "pgdown", "tab":
+	default:
+		*t.prompt += key
+	}
+}
+
+// move moves the selection, or scrolls the article, by delta rows
+func (t *tui) move(delta int) error {
+	switch t.focus {
+	case paneSources:
+		next := clamp(t.sourceIdx+delta, 0, len(t.sources)-1)
+		if next != t.sourceIdx {
+			t.sourceIdx = next
+			return t.loadPosts()
+		}
+	case panePosts:
+		t.postIdx = clamp(t.postIdx+delta, 0, len(t.posts)-1)
+		t.articleTop = 0
+		if t.postIdx >= len(t.posts)-1 && t.more {
+			return t.loadMorePosts()
+		}
+	case paneArticle:
+		t.articleTop = max(t.articleTop+delta, 0)
+	}
+	return nil
+}
+
+func clamp(value, low, high int) int {
+	return max(low, min(value, high))
+}
+
+// open moves into the next pane; opening a post marks it read
+func (t *tui) open() error {
+	switch t.focus {
+	case paneSources:
+		t.focus = panePosts
+	case panePosts:
+		post := t.selectedPost()
+		if post == nil {
+			return nil
+		}
+		t.focus = paneArticle
+		t.articleTop = 0
+		if !post.Read {
+			return t.toggleRead()
+		}
+	}
+	return nil
+}
+
+func (t *tui) selectedPost() *tuiPost {
+	if t.postIdx < 0 || t.postIdx >= len(t.posts) {
+		return nil
+	}
+	return &t.posts[t.postIdx]
+}
+
+func (t *tui) toggleRead() error {
+	post := t.selectedPost()
+	if post == nil {
+		return nil
+	}
+	var err error
+	if post.Read {
+		_, err = t.state.DB.MarkPostUnread(t.ctx, database.MarkPostUnreadParams{UserID: t.user.ID, PostID: post.ID})
+	} else {
+		_, err = t.state.DB.MarkPostRead(t.ctx, database.MarkPostReadParams{UserID: t.user.ID, ReadAt: time.Now().UTC(), PostID: post.ID})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update read state of '%s': %w", post.Title, err)
+	}
+	post.Read = !post.Read
+	return t.loadSources()
+}
+
+func (t *tui) toggleStar() error {
+	post := t.selectedPost()
+	if post == nil {
+		return nil
+	}
+	var err error
+	if post.Starred {
+		_, err = t.state.DB.UnstarPost(t.ctx, database.UnstarPostParams{UserID: t.user.ID, PostID: post.ID})
+	} else {
+		err = t.state.DB.StarPost(t.ctx, database.StarPostParams{UserID: t.user.ID, PostID: post.ID, StarredAt: time.Now().UTC()})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update star of '%s': %w", post.Title, err)
+	}
+	post.Starred = !post.Starred
+	return nil
+}
+
+// refresh fetches the selected feed, or every followed feed, and reloads in place
+func (t *tui) refresh() error {
+	source := t.sources[t.sourceIdx]
+	var feedIDs []uuid2.UUID
+	if source.FeedID.Valid {
+		feedIDs = append(feedIDs, source.FeedID.UUID)
+	} else {
+		feeds, err := t.state.DB.GetFollowedFeedsForUser(t.ctx, t.user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get followed feeds for user '%s': %w", t.user.Name, err)
+		}
+		for _, feed := range feeds {
+			feedIDs = append(feedIDs, feed.ID)
+		}
+	}
+
+	t.status = fmt.Sprintf("Fetching %d feeds...", len(feedIDs))
+	t.draw()
+
+	var saved, failed int
+	for _, id := range feedIDs {
+		feed, err := t.state.DB.GetFeed(t.ctx, id)
+		if err != nil {
+			failed++
+			continue
+		}
+		// fetching reports progress that would scribble over the screen
+		result, err := scrapeFeed(t.ctx, t.state, feed, quietScrapeOutput)
+		saved += result.Saved
+		if err != nil {
+			failed++
+		}
+	}
+
+	if err := t.loadSources(); err != nil {
+		return err
+	}
+	selected := t.selectedPost()
+	var selectedID uuid2.UUID
+	if selected != nil {
+		selectedID = selected.ID
+	}
+	if err := t.loadPosts(); err != nil {
+		return err
+	}
+	for i, post := range t.posts {
+		if post.ID == selectedID {
+			t.postIdx = i
+		}
+	}
+
+	t.status = fmt.Sprintf("Fetched %d feeds: %d new posts", len(feedIDs), saved)
+	if failed > 0 {
+		t.status += fmt.Sprintf(", %d failed", failed)
+	}
+	return nil
+}
+
+// openLink opens the selected post in $BROWSER, handing it the terminal until it exits
+func (t *tui) openLink() error {
+	post := t.selectedPost()
+	if post == nil {
+		return nil
+	}
+
+	browser, _, _ := strings.Cut(os.Getenv("BROWSER"), ":")
+	if browser == "" {
+		browser = "xdg-open"
+		if runtime.GOOS == "darwin" {
+			browser = "open"
+		}
+	}
+
+	restore, err := suspendRawMode()
+	if err != nil {
+		return err
+	}
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	t.out.Flush()
+
+	cmd := exec.Command(browser, post.Url)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	runErr := cmd.Run()
+
+	restore()
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	if runErr != nil {
+		return fmt.Errorf("failed to open %s with %s: %w", post.Url, browser, runErr)
+	}
+	return nil
+}
+
+// paneHeight is the number of list rows in the posts pane
+func (t *tui) paneHeight() int {
+	return max((t.height-1)*2/5-1, 1)
+}
+
+// draw renders the whole screen
+func (t *tui) draw() {
+	if t.width < 40 || t.height < 10 {
+		t.out.WriteString("\x1b[H\x1b[2J" + fit("Terminal too small", t.width))
+		t.out.Flush()
+		return
+	}
+
+	bodyHeight := t.height - 1
+	sourcesWidth := min(28, t.width/4)
+	rightWidth := t.width - sourcesWidth - 1
+	listHeight := t.paneHeight() + 1
+
+	sources := t.sourceLines(sourcesWidth, bodyHeight)
+	posts := t.postLines(rightWidth, listHeight)
+	article := t.articleLines(rightWidth, bodyHeight-listHeight-1)
+
+	for y := 0; y < bodyHeight; y++ {
+		var right string
+		switch {
+		case y < listHeight:
+			right = posts[y]
+		case y == listHeight:
+			right = ansiDim + strings.Repeat("─", rightWidth) + ansiReset
+		default:
+			right = article[y-listHeight-1]
+		}
+		fmt.Fprintf(t.out, "\x1b[%d;1H%s%s│%s%s", y+1, sources[y], ansiDim, ansiReset, right)
+	}
+
+	status := tuiHelp
+	if t.prompt != nil {
+		status = "Search: " + *t.prompt + "█"
+	} else if t.status != "" {
+		status = t.status
+	}
+	fmt.Fprintf(t.out, "\x1b[%d;1H%s%s%s", t.height, ansiReverse, fit(status, t.width), ansiReset)
+	t.out.Flush()
+}
+
+// paneTitle renders a pane's heading, underlined when the pane has focus
+func (t *tui) paneTitle(title string, width, pane int) string {
+	style := ansiBold
+	if t.focus == pane {
+		style += ansiUnderline
+	}
+	return style + fit(title, width) + ansiReset
+}
+
+// selectionStyle is how the selected row of pane is drawn
+func (t *tui) selectionStyle(pane int) string {
+	if t.focus == pane {
+		return ansiReverse
+	}
+	return ansiUnderline
+}
+
+func (t *tui) sourceLines(width, height int) []string {
+	lines := []string{t.paneTitle("Feeds", width, paneSources)}
+	rows := height - 1
+	t.sourceTop = scrollTo(t.sourceIdx, t.sourceTop, rows)
+	for i := t.sourceTop; i < len(t.sources) && len(lines) < height; i++ {
+		source := t.sources[i]
+		label := fmt.Sprintf(" %s", source.Label)
+		count := ""
+		if source.Unread > 0 {
+			count = fmt.Sprintf(" %d ", source.Unread)
+		}
+		text := fit(label, width-utf8.RuneCountInString(count)) + count
+		style := ""
+		if source.Unread > 0 {
+			style = ansiBold
+		}
+		if i == t.sourceIdx {
+			style += t.selectionStyle(paneSources)
+		}
+		lines = append(lines, style+text+ansiReset)
+	}
+	return padLines(lines, width, height)
+}
+
+func (t *tui) postLines(width, height int) []string {
+	title := "Posts: " + t.sources[t.sourceIdx].Label
+	if t.searchQuery != "" {
+		title = "Search: " + t.searchQuery + " (esc to go back)"
+	} else if t.unreadOnly {
+		title += " (unread)"
+	}
+	lines := []string{t.paneTitle(title, width, panePosts)}
+	if len(t.posts) == 0 {
+		lines = append(lines, fit(" No posts", width))
+	}
+
+	rows := height - 1
+	t.postTop = scrollTo(t.postIdx, t.postTop, rows)
+	for i := t.postTop; i < len(t.posts) && len(lines) < height; i++ {
+		post := t.posts[i]
+		mark := " "
+		switch {
+		case post.Starred:
+			mark = "*"
+		case !post.Read:
+			mark = "•"
+		}
+		text := fmt.Sprintf("%s %s  %s — %s", mark, post.Date.Format("Jan 02"), post.Title, post.FeedName)
+
+		var style string
+		switch {
+		case post.Highlight:
+			style = ansiYellow
+		case post.Read:
+			style = ansiDim
+		default:
+			style = ansiBold
+		}
+		if i == t.postIdx {
+			style += t.selectionStyle(panePosts)
+		}
+		lines = append(lines, style+fit(text, width)+ansiReset)
+	}
+	return padLines(lines, width, height)
+}
+
+func (t *tui) articleLines(width, height int) []string {
+	post := t.selectedPost()
+	if post == nil {
+		return padLines(nil, width, height)
+	}
+
+	lines := []string{t.paneTitle(post.Title, width, paneArticle)}
+	lines = append(lines, ansiDim+fit(fmt.Sprintf("%s · %s · [%d]", post.FeedName, post.Date.Format(time.RFC1123), post.Handle), width)+ansiReset)
+	lines = append(lines, ansiDim+fit(post.Url, width)+ansiReset, fit("", width))
+
+	body := wrapText(htmlToText(post.Body), width-1)
+	t.articleTop = min(t.articleTop, max(len(body)-1, 0))
+	for _, line := range body[t.articleTop:] {
+		if len(lines) >= height {
+			break
+		}
+		lines = append(lines, fit(" "+line, width))
+	}
+	return padLines(lines, width, height)
+}
+
+// scrollTo returns the first visible row so that selected stays within rows visible rows
+func scrollTo(selected, top, rows int) int {
+	if selected < top {
+		return selected
+	}
+	if selected >= top+rows {
+		return selected - rows + 1
+	}
+	return top
+}
+
+// padLines fills lines up to height blank rows of width
+func padLines(lines []string, width, height int) []string {
+	for len(lines) < height {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	return lines[:height]
+}
+
+// fit pads or truncates s to exactly width runes
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < ' ' {
+			return ' '
+		}
+		return r
+	}, s)
+	count := utf8.RuneCountInString(s)
+	if count > width {
+		runes := []rune(s)
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-count)
+}
@@ -0,0 +1,580 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/maevlava/Gator/internal/models"
+	"io"
+	neturl "net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Namespaces the validator checks elements against
+const (
+	nsAtom = "http://www.w3.org/2005/Atom"
+	nsRDF  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsRSS1 = "http://purl.org/rss/1.0/"
+)
+
+// Diagnostic severities, in increasing order of badness
+const (
+	severityWarning = "warning"
+	severityError   = "error"
+)
+
+// diagnostic is a single validation finding; Line is 0 when it applies to the whole document
+type diagnostic struct {
+	Severity string
+	Line     int
+	Message  string
+}
+
+type diagnostics []diagnostic
+
+func (d *diagnostics) errorf(line int, format string, args ...any) {
+	*d = append(*d, diagnostic{Severity: severityError, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *diagnostics) warnf(line int, format string, args ...any) {
+	*d = append(*d, diagnostic{Severity: severityWarning, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// xmlNode is an element with the line it started on, enough to point at problems
+type xmlNode struct {
+	Name     xml.Name
+	Attr     []xml.Attr
+	Text     string
+	Line     int
+	Children []*xmlNode
+}
+
+func (n *xmlNode) child(space, local string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name.Space == space && c.Name.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *xmlNode) all(space, local string) []*xmlNode {
+	var found []*xmlNode
+	for _, c := range n.Children {
+		if c.Name.Space == space && c.Name.Local == local {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+func (n *xmlNode) attr(space, local string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func (n *xmlNode) text() string {
+	return strings.TrimSpace(n.Text)
+}
+
+// buildXMLTree reads the document into xmlNodes. Strict mode reports the first
+// well-formedness error; the lenient decoder is then used so other rules still run.
+func buildXMLTree(body []byte, diags *diagnostics) (*xmlNode, error) {
+	strict := xml.NewDecoder(bytes.NewReader(body))
+	strict.CharsetReader = charsetReader
+	root, err := readXMLTree(strict)
+	if err == nil {
+		return root, nil
+	}
+
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		diags.errorf(syntaxErr.Line, "not well-formed XML: %s", syntaxErr.Msg)
+	} else {
+		diags.errorf(0, "not well-formed XML: %v", err)
+	}
+	return readXMLTree(newXMLDecoder(bytes.NewReader(body)))
+}
+
+func readXMLTree(decoder *xml.Decoder) (*xmlNode, error) {
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return root, err
+		}
+		line, _ := decoder.InputPos()
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name, Attr: t.Attr, Line: line}
+			if len(stack) == 0 {
+				if root == nil {
+					root = node
+				}
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("no root element")
+	}
+	return root, nil
+}
+
+var xmlEncodingPattern = regexp.MustCompile(`^\s*<\?xml[^>]*encoding=["']([^"']+)["']`)
+
+// checkEncoding compares the declared encoding with the bytes actually sent
+func checkEncoding(body []byte, format string, diags *diagnostics) {
+	declared := "utf-8"
+	if format == models.FormatJSONFeed {
+		if !utf8.Valid(body) {
+			diags.errorf(invalidUTF8Line(body), "JSON Feed must be UTF-8 but contains invalid UTF-8")
+		}
+		return
+	}
+	if m := xmlEncodingPattern.FindSubmatch(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))); m != nil {
+		declared = strings.ToLower(string(m[1]))
+	}
+
+	switch declared {
+	case "utf-8", "utf8":
+		if !utf8.Valid(body) {
+			diags.errorf(invalidUTF8Line(body), "declared encoding is UTF-8 but the document contains invalid UTF-8")
+		}
+	case "us-ascii", "ascii":
+		for i, b := range body {
+			if b >= 0x80 {
+				diags.errorf(bytes.Count(body[:i], []byte("\n"))+1, "declared encoding is US-ASCII but the document contains non-ASCII bytes")
+				break
+			}
+		}
+	default:
+		if bytes.HasPrefix(body, []byte("\xef\xbb\xbf")) {
+			diags.errorf(1, "document starts with a UTF-8 byte order mark but declares encoding %s", declared)
+		} else if utf8.Valid(body) && hasMultiByteRune(body) {
+			diags.warnf(1, "declared encoding is %s but the document looks like UTF-8", declared)
+		}
+		if _, err := charsetReader(declared, bytes.NewReader(nil)); err != nil {
+			diags.warnf(1, "gator cannot decode the declared encoding %s", declared)
+		}
+	}
+}
+
+func invalidUTF8Line(body []byte) int {
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRune(body[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return bytes.Count(body[:i], []byte("\n")) + 1
+		}
+		i += size
+	}
+	return 0
+}
+
+func hasMultiByteRune(body []byte) bool {
+	for _, b := range body {
+		if b >= 0x80 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFeed checks a document against the rules of its format.
+// The aggregator's parser runs last so validation sees exactly what gator would store.
+func validateFeed(body []byte, baseURL string) (string, diagnostics) {
+	var diags diagnostics
+	format, err := detectFormat(body)
+	if err != nil {
+		diags.errorf(0, "%v", err)
+		return "", diags
+	}
+	checkEncoding(body, format, &diags)
+
+	switch format {
+	case models.FormatJSONFeed:
+		validateJSONFeed(body, &diags)
+	default:
+		root, err := buildXMLTree(body, &diags)
+		if err != nil && root == nil {
+			diags.errorf(0, "failed to read document: %v", err)
+			return format, diags
+		}
+		switch format {
+		case models.FormatRSS2:
+			validateRSS2(root, &diags)
+		case models.FormatRSS1:
+			validateRSS1(root, &diags)
+		case models.FormatAtom:
+			validateAtom(root, &diags)
+		}
+	}
+
+	if _, err := parseFeed(body, baseURL); err != nil {
+		diags.errorf(0, "gator could not parse the feed: %v", err)
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+	return format, diags
+}
+
+// rfc822Layouts are the date formats RSS 2.0 allows
+var rfc822Layouts = []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", time.RFC822Z, time.RFC822}
+
+func checkRFC822(node *xmlNode, diags *diagnostics) {
+	value := node.text()
+	for _, layout := range rfc822Layouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return
+		}
+	}
+	if _, err := parseDate(value); err == nil {
+		diags.warnf(node.Line, "<%s> '%s' is not an RFC 822 date", node.Name.Local, value)
+		return
+	}
+	diags.errorf(node.Line, "<%s> '%s' is not a valid date", node.Name.Local, value)
+}
+
+func checkRFC3339(node *xmlNode, diags *diagnostics) {
+	value := node.text()
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return
+	}
+	if _, err := parseDate(value); err == nil {
+		diags.warnf(node.Line, "<%s> '%s' is not an RFC 3339 date", node.Name.Local, value)
+		return
+	}
+	diags.errorf(node.Line, "<%s> '%s' is not a valid date", node.Name.Local, value)
+}
+
+// checkLink reports missing and relative links; required links are errors when relative
+func checkLink(line int, what, link string, required bool, diags *diagnostics) {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return
+	}
+	parsed, err := neturl.Parse(link)
+	switch {
+	case err != nil:
+		diags.errorf(line, "%s '%s' is not a valid URL", what, link)
+	case !parsed.IsAbs() && required:
+		diags.errorf(line, "%s '%s' is relative, it must be absolute", what, link)
+	case !parsed.IsAbs():
+		diags.warnf(line, "%s '%s' is relative", what, link)
+	}
+}
+
+// requireChildren reports each missing required child element
+func requireChildren(node *xmlNode, space string, names []string, diags *diagnostics) {
+	for _, name := range names {
+		if c := node.child(space, name); c == nil || c.text() == "" && len(c.Children) == 0 && len(c.Attr) == 0 {
+			diags.errorf(node.Line, "<%s> is missing required <%s>", node.Name.Local, name)
+		}
+	}
+}
+
+func validateRSS2(root *xmlNode, diags *diagnostics) {
+	if version, _ := root.attr("", "version"); version != "2.0" {
+		diags.warnf(root.Line, "<rss> version is '%s', expected 2.0", version)
+	}
+	channels := root.all("", "channel")
+	if len(channels) == 0 {
+		diags.errorf(root.Line, "<rss> is missing required <channel>")
+		return
+	}
+	if len(channels) > 1 {
+		diags.errorf(channels[1].Line, "<rss> must contain exactly one <channel>")
+	}
+	channel := channels[0]
+	requireChildren(channel, "", []string{"title", "link", "description"}, diags)
+	if link := channel.child("", "link"); link != nil {
+		checkLink(link.Line, "channel <link>", link.text(), true, diags)
+	}
+	for _, name := range []string{"pubDate", "lastBuildDate"} {
+		if date := channel.child("", name); date != nil {
+			checkRFC822(date, diags)
+		}
+	}
+
+	guids := map[string]int{}
+	for _, item := range channel.all("", "item") {
+		title, description := item.child("", "title"), item.child("", "description")
+		if (title == nil || title.text() == "") && (description == nil || description.text() == "") {
+			diags.errorf(item.Line, "<item> must contain a <title> or <description>")
+		}
+		link := item.child("", "link")
+		if link != nil {
+			checkLink(link.Line, "item <link>", link.text(), false, diags)
+		}
+		if date := item.child("", "pubDate"); date != nil {
+			checkRFC822(date, diags)
+		} else {
+			diags.warnf(item.Line, "<item> has no <pubDate>")
+		}
+
+		guid := item.child("", "guid")
+		if guid == nil {
+			if link == nil {
+				diags.warnf(item.Line, "<item> has neither <link> nor <guid>, gator will skip it")
+			}
+			continue
+		}
+		value := guid.text()
+		if first, seen := guids[value]; seen {
+			diags.errorf(guid.Line, "duplicate <guid> '%s', first used on line %d", value, first)
+		} else {
+			guids[value] = guid.Line
+		}
+		if permalink, _ := guid.attr("", "isPermaLink"); permalink != "false" {
+			if parsed, err := neturl.Parse(value); err != nil || !parsed.IsAbs() {
+				diags.warnf(guid.Line, "<guid> '%s' is not a URL, add isPermaLink=\"false\"", value)
+			}
+		}
+	}
+}
+
+func validateRSS1(root *xmlNode, diags *diagnostics) {
+	if root.Name.Space != nsRDF || root.Name.Local != "RDF" {
+		diags.errorf(root.Line, "root element must be rdf:RDF in the %s namespace", nsRDF)
+	}
+	channel := root.child(nsRSS1, "channel")
+	if channel == nil {
+		if root.child("", "channel") != nil {
+			diags.errorf(root.Line, "RSS 1.0 elements must be in the %s namespace", nsRSS1)
+		} else {
+			diags.errorf(root.Line, "<rdf:RDF> is missing required <channel>")
+		}
+		return
+	}
+	if _, ok := channel.attr(nsRDF, "about"); !ok {
+		diags.errorf(channel.Line, "<channel> is missing required rdf:about")
+	}
+	requireChildren(channel, nsRSS1, []string{"title", "link", "description", "items"}, diags)
+	if link := channel.child(nsRSS1, "link"); link != nil {
+		checkLink(link.Line, "channel <link>", link.text(), true, diags)
+	}
+
+	abouts := map[string]int{}
+	for _, item := range root.all(nsRSS1, "item") {
+		about, ok := item.attr(nsRDF, "about")
+		if !ok {
+			diags.errorf(item.Line, "<item> is missing required rdf:about")
+		} else if first, seen := abouts[about]; seen {
+			diags.errorf(item.Line, "duplicate <item> rdf:about '%s', first used on line %d", about, first)
+		} else {
+			abouts[about] = item.Line
+		}
+		requireChildren(item, nsRSS1, []string{"title", "link"}, diags)
+		if link := item.child(nsRSS1, "link"); link != nil {
+			checkLink(link.Line, "item <link>", link.text(), false, diags)
+		}
+		if date := item.child("http://purl.org/dc/elements/1.1/", "date"); date != nil {
+			checkRFC3339(date, diags)
+		}
+	}
+}
+
+func validateAtom(root *xmlNode, diags *diagnostics) {
+	if root.Name.Space != nsAtom {
+		diags.errorf(root.Line, "<feed> must be in the %s namespace", nsAtom)
+		return
+	}
+	requireChildren(root, nsAtom, []string{"id", "title", "updated"}, diags)
+	if updated := root.child(nsAtom, "updated"); updated != nil {
+		checkRFC3339(updated, diags)
+	}
+	for _, link := range root.all(nsAtom, "link") {
+		href, _ := link.attr("", "href")
+		checkLink(link.Line, "feed <link>", href, false, diags)
+	}
+
+	feedHasAuthor := root.child(nsAtom, "author") != nil
+	ids := map[string]int{}
+	for _, entry := range root.all(nsAtom, "entry") {
+		requireChildren(entry, nsAtom, []string{"id", "title", "updated"}, diags)
+		if !feedHasAuthor && entry.child(nsAtom, "author") == nil {
+			diags.errorf(entry.Line, "<entry> has no <author> and neither does <feed>")
+		}
+		for _, name := range []string{"updated", "published"} {
+			if date := entry.child(nsAtom, name); date != nil {
+				checkRFC3339(date, diags)
+			}
+		}
+		if id := entry.child(nsAtom, "id"); id != nil {
+			if first, seen := ids[id.text()]; seen {
+				diags.errorf(id.Line, "duplicate <id> '%s', first used on line %d", id.text(), first)
+			} else {
+				ids[id.text()] = id.Line
+			}
+		}
+
+		hasAlternate := false
+		for _, link := range entry.all(nsAtom, "link") {
+			href, _ := link.attr("", "href")
+			if href == "" {
+				diags.errorf(link.Line, "<link> is missing required href")
+				continue
+			}
+			if rel, _ := link.attr("", "rel"); rel == "" || rel == "alternate" {
+				hasAlternate = true
+			}
+			checkLink(link.Line, "entry <link>", href, false, diags)
+		}
+		if !hasAlternate && entry.child(nsAtom, "content") == nil {
+			diags.errorf(entry.Line, "<entry> must have an alternate <link> or <content>")
+		}
+	}
+}
+
+// lineAt converts a byte offset to a 1-based line number
+func lineAt(body []byte, offset int64) int {
+	if offset > int64(len(body)) {
+		offset = int64(len(body))
+	}
+	return bytes.Count(body[:offset], []byte("\n")) + 1
+}
+
+func validateJSONFeed(body []byte, diags *diagnostics) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(body, &top); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			diags.errorf(lineAt(body, syntaxErr.Offset), "invalid JSON: %v", err)
+		} else {
+			diags.errorf(0, "invalid JSON Feed: %v", err)
+		}
+		return
+	}
+
+	var feed models.JSONFeed
+	if err := json.Unmarshal(body, &feed); err != nil {
+		diags.errorf(0, "invalid JSON Feed: %v", err)
+		return
+	}
+	switch feed.Version {
+	case "https://jsonfeed.org/version/1", "https://jsonfeed.org/version/1.1":
+	case "":
+		diags.errorf(1, "missing required \"version\"")
+	default:
+		diags.errorf(1, "unknown version '%s'", feed.Version)
+	}
+	if feed.Title == "" {
+		diags.errorf(1, "missing required \"title\"")
+	}
+	if _, ok := top["items"]; !ok {
+		diags.errorf(1, "missing required \"items\"")
+		return
+	}
+	checkLink(1, "home_page_url", feed.HomePageURL, true, diags)
+	checkLink(1, "feed_url", feed.FeedURL, true, diags)
+
+	// walk items with a token decoder so each one can be reported with its line
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if err := seekJSONKey(decoder, "items"); err != nil {
+		diags.errorf(0, "invalid \"items\": %v", err)
+		return
+	}
+	ids := map[string]int{}
+	for decoder.More() {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			diags.errorf(lineAt(body, decoder.InputOffset()), "invalid item: %v", err)
+			return
+		}
+		line := lineAt(body, decoder.InputOffset()-int64(len(raw)))
+
+		var fields map[string]json.RawMessage
+		var item models.JSONFeedItem
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			diags.errorf(line, "item is not an object")
+			continue
+		}
+		if err := json.Unmarshal(raw, &item); err != nil {
+			diags.errorf(line, "invalid item: %v", err)
+			continue
+		}
+
+		switch id := item.ID.(type) {
+		case nil:
+			diags.errorf(line, "item is missing required \"id\"")
+		case string:
+			if first, seen := ids[id]; seen {
+				diags.errorf(line, "duplicate item id '%s', first used on line %d", id, first)
+			} else {
+				ids[id] = line
+			}
+		default:
+			diags.warnf(line, "item \"id\" should be a string, got %s", string(fields["id"]))
+		}
+		if item.ContentHTML == "" && item.ContentText == "" {
+			diags.errorf(line, "item must have \"content_html\" or \"content_text\"")
+		}
+		checkLink(line, "item url", item.URL, false, diags)
+		for _, date := range [][2]string{{"date_published", item.DatePublished}, {"date_modified", item.DateModified}} {
+			name, value := date[0], date[1]
+			if value == "" {
+				continue
+			}
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				if _, err := parseDate(value); err == nil {
+					diags.warnf(line, "item %s '%s' is not an RFC 3339 date", name, value)
+				} else {
+					diags.errorf(line, "item %s '%s' is not a valid date", name, value)
+				}
+			}
+		}
+	}
+}
+
+// seekJSONKey advances decoder to just inside the array stored under a top-level key
+func seekJSONKey(decoder *json.Decoder, key string) error {
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if name, ok := token.(string); ok && name == key {
+			open, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			if delim, ok := open.(json.Delim); !ok || delim != '[' {
+				return errors.New("expected an array")
+			}
+			return nil
+		}
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("key %q not found", key)
+}
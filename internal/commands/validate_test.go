@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"github.com/maevlava/Gator/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestValidateFeed(t *testing.T) {
+	type finding struct {
+		severity string
+		line     int
+		message  string
+	}
+	tests := []struct {
+		name       string
+		body       string
+		wantFormat string
+		want       []finding
+	}{
+		{
+			name: "valid rss 2.0",
+			body: `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0">
+<channel>
+  <title>Example</title>
+  <link>https://example.com/</link>
+  <description>News</description>
+  <item>
+    <title>First</title>
+    <link>https://example.com/first</link>
+    <guid>https://example.com/first</guid>
+    <pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate>
+  </item>
+</channel>
+</rss>`,
+			wantFormat: models.FormatRSS2,
+		},
+		{
+			name: "broken rss 2.0",
+			body: `<rss version="0.92">
+<channel>
+  <title>Example</title>
+  <link>/home</link>
+  <item>
+    <title>First</title>
+    <guid>a</guid>
+    <pubDate>2006-01-02</pubDate>
+  </item>
+  <item>
+    <description>Second</description>
+    <guid isPermaLink="false">a</guid>
+    <pubDate>someday</pubDate>
+  </item>
+  <item><pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate></item>
+</channel>
+</rss>`,
+			wantFormat: models.FormatRSS2,
+			want: []finding{
+				{severityWarning, 1, "version is '0.92'"},
+				{severityError, 2, "missing required <description>"},
+				{severityError, 4, "channel <link> '/home' is relative"},
+				{severityWarning, 7, "<guid> 'a' is not a URL"},
+				{severityWarning, 8, "'2006-01-02' is not an RFC 822 date"},
+				{severityError, 12, "duplicate <guid> 'a', first used on line 7"},
+				{severityError, 13, "'someday' is not a valid date"},
+				{severityError, 15, "must contain a <title> or <description>"},
+				{severityWarning, 15, "neither <link> nor <guid>"},
+			},
+		},
+		{
+			name: "not well-formed",
+			body: `<rss version="2.0">
+<channel>
+  <title>Example</title>
+  <link>https://example.com/</link>
+  <description>News &amp; views</description>
+  <item><title>Oops</item>
+</channel>
+</rss>`,
+			wantFormat: models.FormatRSS2,
+			want: []finding{
+				{severityError, 6, "not well-formed XML"},
+				{severityWarning, 6, "has no <pubDate>"},
+				{severityWarning, 6, "neither <link> nor <guid>"},
+			},
+		},
+		{
+			name: "non-ascii bytes in us-ascii",
+			body: "<?xml version=\"1.0\" encoding=\"us-ascii\"?>\n<rss version=\"2.0\"><channel>\n<title>Café</title>" +
+				"<link>https://example.com/</link><description>d</description></channel></rss>",
+			wantFormat: models.FormatRSS2,
+			want: []finding{
+				{severityError, 3, "declared encoding is US-ASCII but the document contains non-ASCII bytes"},
+			},
+		},
+		{
+			name: "valid rss 1.0",
+			body: `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+  <channel rdf:about="https://rdf.example.com/">
+    <title>RDF</title>
+    <link>https://rdf.example.com/</link>
+    <description>Old school</description>
+    <items><rdf:Seq><rdf:li rdf:resource="https://rdf.example.com/item"/></rdf:Seq></items>
+  </channel>
+  <item rdf:about="https://rdf.example.com/item">
+    <title>Item</title>
+    <link>https://rdf.example.com/item</link>
+  </item>
+</rdf:RDF>`,
+			wantFormat: models.FormatRSS1,
+		},
+		{
+			name: "broken rss 1.0",
+			body: `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>RDF</title>
+    <link>https://rdf.example.com/</link>
+    <description>Old school</description>
+  </channel>
+  <item>
+    <title>Item</title>
+    <dc:date>March 1st</dc:date>
+  </item>
+</rdf:RDF>`,
+			wantFormat: models.FormatRSS1,
+			want: []finding{
+				{severityError, 3, "missing required rdf:about"},
+				{severityError, 3, "missing required <items>"},
+				{severityError, 8, "missing required rdf:about"},
+				{severityError, 8, "missing required <link>"},
+				{severityError, 10, "'March 1st' is not a valid date"},
+			},
+		},
+		{
+			name: "valid atom",
+			body: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:uuid:feed</id>
+  <title>Atom</title>
+  <updated>2024-05-06T07:08:09Z</updated>
+  <author><name>Bo</name></author>
+  <link href="https://atom.example.com/"/>
+  <entry>
+    <id>urn:uuid:1</id>
+    <title>Entry</title>
+    <updated>2024-05-06T07:08:09+02:00</updated>
+    <link href="https://atom.example.com/1"/>
+  </entry>
+</feed>`,
+			wantFormat: models.FormatAtom,
+		},
+		{
+			name: "broken atom",
+			body: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:uuid:feed</id>
+  <title>Atom</title>
+  <updated>2024-05-06</updated>
+  <entry>
+    <id>urn:uuid:1</id>
+    <title>One</title>
+    <updated>2024-05-06T07:08:09Z</updated>
+    <author><name>Bo</name></author>
+    <link href="https://atom.example.com/1"/>
+  </entry>
+  <entry>
+    <id>urn:uuid:1</id>
+    <updated>yesterday</updated>
+    <link rel="related"/>
+  </entry>
+</feed>`,
+			wantFormat: models.FormatAtom,
+			want: []finding{
+				{severityWarning, 5, "'2024-05-06' is not an RFC 3339 date"},
+				{severityError, 13, "missing required <title>"},
+				{severityError, 13, "has no <author> and neither does <feed>"},
+				{severityError, 13, "must have an alternate <link> or <content>"},
+				{severityError, 14, "duplicate <id> 'urn:uuid:1', first used on line 7"},
+				{severityError, 15, "'yesterday' is not a valid date"},
+				{severityError, 16, "missing required href"},
+			},
+		},
+		{
+			name:       "atom in the wrong namespace",
+			body:       `<feed><title>T</title></feed>`,
+			wantFormat: models.FormatAtom,
+			want: []finding{
+				{severityError, 1, "must be in the http://www.w3.org/2005/Atom namespace"},
+			},
+		},
+		{
+			name: "valid json feed",
+			body: `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON",
+  "home_page_url": "https://json.example.com/",
+  "items": [
+    {"id": "1", "url": "https://json.example.com/1", "content_text": "hi", "date_published": "2024-01-02T03:04:05Z"}
+  ]
+}`,
+			wantFormat: models.FormatJSONFeed,
+		},
+		{
+			name: "broken json feed",
+			body: `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "home_page_url": "json.example.com",
+  "items": [
+    {"id": "1", "content_text": "one"},
+    {"id": "1", "content_html": "<p>two</p>", "date_published": "2024-01-02"},
+    {"id": 3, "url": "/3", "content_text": "three"},
+    {"content_text": "four", "date_modified": "soon"},
+    {"id": "5"}
+  ]
+}`,
+			wantFormat: models.FormatJSONFeed,
+			want: []finding{
+				{severityError, 1, `missing required "title"`},
+				{severityError, 1, "home_page_url 'json.example.com' is relative"},
+				{severityError, 6, "duplicate item id '1', first used on line 5"},
+				{severityWarning, 6, "'2024-01-02' is not an RFC 3339 date"},
+				{severityWarning, 7, `"id" should be a string, got 3`},
+				{severityWarning, 7, "item url '/3' is relative"},
+				{severityError, 8, `missing required "id"`},
+				{severityError, 8, "'soon' is not a valid date"},
+				{severityError, 9, `must have "content_html" or "content_text"`},
+			},
+		},
+		{
+			name: "json syntax error",
+			body: `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "JSON",
+  "items": [
+    {"id": "1",}
+  ]
+}`,
+			wantFormat: models.FormatJSONFeed,
+			want: []finding{
+				{severityError, 0, "gator could not parse the feed"},
+				{severityError, 5, "invalid JSON"},
+			},
+		},
+		{
+			name: "unknown document",
+			body: `<html><body>hi</body></html>`,
+			want: []finding{
+				{severityError, 0, ""},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			format, diags := validateFeed([]byte(test.body), "")
+			if format != test.wantFormat {
+				t.Errorf("format = %q, want %q", format, test.wantFormat)
+			}
+			if len(diags) != len(test.want) {
+				t.Fatalf("got %d diagnostics, want %d:\n%v", len(diags), len(test.want), diags)
+			}
+			for i, want := range test.want {
+				got := diags[i]
+				if got.Severity != want.severity || got.Line != want.line || !strings.Contains(got.Message, want.message) {
+					t.Errorf("diagnostic %d = %s on line %d: %s\nwant %s on line %d containing %q",
+						i, got.Severity, got.Line, got.Message, want.severity, want.line, want.message)
+				}
+			}
+		})
+	}
+}
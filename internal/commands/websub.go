@@ -0,0 +1,320 @@
+package commands
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	uuid2 "github.com/google/uuid"
+	"github.com/maevlava/Gator/internal/config"
+	"github.com/maevlava/Gator/internal/database"
+	"github.com/maevlava/Gator/internal/models"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// webSubLease is the lease we ask hubs for; they may grant less
+	webSubLease = 7 * 24 * time.Hour
+	// webSubRenewBefore is how long before expiry a lease is renewed
+	webSubRenewBefore = time.Hour
+	// webSubVerifyTimeout is how long a subscription request waits for the hub to verify it before it is retried
+	webSubVerifyTimeout = 15 * time.Minute
+	// webSubMaxBody bounds pushed content
+	webSubMaxBody = 10 << 20
+)
+
+// webSub is set while agg serves WebSub callbacks; fetches only subscribe when it is
+var webSub *webSubscriber
+
+// webSubscriber subscribes feeds to their hubs and ingests pushed content.
+// Callbacks are served at <callback url>/websub/<subscription id>.
+type webSubscriber struct {
+	state       *config.State
+	callbackURL string
+	server      *http.Server
+}
+
+func newWebSubscriber(state *config.State) *webSubscriber {
+	w := &webSubscriber{
+		state:       state,
+		callbackURL: strings.TrimSuffix(state.Config.WebSubCallbackURL, "/"),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /websub/{id}", w.handleVerification)
+	mux.HandleFunc("POST /websub/{id}", w.handleContent)
+	w.server = &http.Server{
+		Addr:              state.Config.WebSubListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return w
+}
+
+// start serves callbacks in the background
+func (w *webSubscriber) start() {
+	go func() {
+		log.Printf("WebSub callbacks listening on %s", w.server.Addr)
+		if err := w.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("WebSub listener failed: %v", err)
+		}
+	}()
+}
+
+// ensureSubscribed subscribes feed to the hub it advertises unless a subscription for the same hub and topic
+// is active or still waiting for the hub to verify it
+func (w *webSubscriber) ensureSubscribed(ctx context.Context, feed database.Feed, parsedFeed *models.ParsedFeed) error {
+	topic := parsedFeed.SelfURL
+	if topic == "" {
+		topic = feed.Url
+	}
+
+	existing, err := w.state.DB.GetWebsubSubscriptionForFeed(ctx, feed.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if err == nil && existing.HubUrl == parsedFeed.HubURL && existing.TopicUrl == topic {
+		switch existing.State {
+		case "active":
+			return nil
+		case "pending":
+			// a request the hub never verified, or that failed to reach it, is retried once it times out
+			if time.Since(existing.UpdatedAt) < webSubVerifyTimeout {
+				return nil
+			}
+		}
+	}
+
+	return w.subscribe(ctx, feed.ID, parsedFeed.HubURL, topic)
+}
+
+// subscribe saves a pending subscription with a new secret and asks hub to push topic to us
+func (w *webSubscriber) subscribe(ctx context.Context, feedID uuid2.UUID, hub, topic string) error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	now := time.Now().UTC()
+	sub, err := w.state.DB.UpsertWebsubSubscription(ctx, database.UpsertWebsubSubscriptionParams{
+		ID:        uuid2.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		FeedID:    feedID,
+		HubUrl:    hub,
+		TopicUrl:  topic,
+		Secret:    hex.EncodeToString(secret),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return w.requestSubscription(ctx, sub)
+}
+
+// requestSubscription asks the hub to push sub's topic to us; the hub confirms asynchronously through handleVerification
+func (w *webSubscriber) requestSubscription(ctx context.Context, sub database.WebsubSubscription) error {
+	hub, topic := sub.HubUrl, sub.TopicUrl
+	form := neturl.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {topic},
+		"hub.callback":      {w.callbackURL + "/websub/" + sub.ID.String()},
+		"hub.secret":        {sub.Secret},
+		"hub.lease_seconds": {strconv.Itoa(int(webSubLease.Seconds()))},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create subscribe request for %s: %w", hub, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Timeout: time.Second * 30}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe at %s: %w", hub, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("hub %s rejected subscription: status code %d", hub, resp.StatusCode)
+	}
+
+	log.Printf("WebSub subscription requested for %s at %s", topic, hub)
+	return nil
+}
+
+// renewLeases resubscribes before hubs let leases lapse. A renewal keeps the subscription active with its
+// secret, so pushes keep arriving while the hub verifies it; one that isn't verified is retried after
+// webSubVerifyTimeout.
+func (w *webSubscriber) renewLeases(ctx context.Context) {
+	now := time.Now().UTC()
+	subs, err := w.state.DB.GetWebsubSubscriptionsToRenew(ctx, database.GetWebsubSubscriptionsToRenewParams{
+		LeaseExpiresAt: sql.NullTime{Time: now.Add(webSubRenewBefore), Valid: true},
+		UpdatedAt:      now.Add(-webSubVerifyTimeout),
+	})
+	if err != nil {
+		log.Printf("Failed to get WebSub subscriptions to renew: %v", err)
+		return
+	}
+	for _, sub := range subs {
+		err := w.state.DB.TouchWebsubSubscription(ctx, database.TouchWebsubSubscriptionParams{
+			UpdatedAt: now,
+			ID:        sub.ID,
+		})
+		if err != nil {
+			log.Printf("Failed to record WebSub renewal for %s: %v", sub.TopicUrl, err)
+			continue
+		}
+		if err := w.requestSubscription(ctx, sub); err != nil {
+			log.Printf("WebSub renewal for %s failed: %v", sub.TopicUrl, err)
+		}
+	}
+}
+
+// lookup finds the subscription a callback url belongs to
+func (w *webSubscriber) lookup(r *http.Request) (database.WebsubSubscription, bool) {
+	id, err := uuid2.Parse(r.PathValue("id"))
+	if err != nil {
+		return database.WebsubSubscription{}, false
+	}
+	sub, err := w.state.DB.GetWebsubSubscription(r.Context(), id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Failed to get WebSub subscription %s: %v", id, err)
+		}
+		return database.WebsubSubscription{}, false
+	}
+	return sub, true
+}
+
+// handleVerification answers the hub's intent verification and denial notices
+func (w *webSubscriber) handleVerification(rw http.ResponseWriter, r *http.Request) {
+	sub, ok := w.lookup(r)
+	query := r.URL.Query()
+	mode, topic := query.Get("hub.mode"), query.Get("hub.topic")
+	if !ok || topic != sub.TopicUrl {
+		http.NotFound(rw, r)
+		return
+	}
+
+	now := time.Now().UTC()
+	switch mode {
+	case "subscribe":
+		if sub.State == "denied" {
+			http.NotFound(rw, r)
+			return
+		}
+		lease, err := strconv.Atoi(query.Get("hub.lease_seconds"))
+		if err != nil || lease <= 0 {
+			lease = int(webSubLease.Seconds())
+		}
+		err = w.state.DB.ActivateWebsubSubscription(r.Context(), database.ActivateWebsubSubscriptionParams{
+			ID:             sub.ID,
+			LeaseExpiresAt: sql.NullTime{Time: now.Add(time.Duration(lease) * time.Second), Valid: true},
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			log.Printf("Failed to activate WebSub subscription for %s: %v", topic, err)
+			http.Error(rw, "failed to activate subscription", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("WebSub subscription for %s active for %ds", topic, lease)
+	case "denied":
+		err := w.state.DB.SetWebsubSubscriptionState(r.Context(), database.SetWebsubSubscriptionStateParams{
+			ID:        sub.ID,
+			State:     "denied",
+			UpdatedAt: now,
+		})
+		if err != nil {
+			log.Printf("Failed to record WebSub denial for %s: %v", topic, err)
+		}
+		log.Printf("WebSub subscription for %s denied: %s", topic, query.Get("hub.reason"))
+		rw.WriteHeader(http.StatusOK)
+		return
+	default:
+		// we never unsubscribe, so any other intent is not ours
+		http.NotFound(rw, r)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/plain")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(rw, query.Get("hub.challenge"))
+}
+
+// handleContent verifies and ingests a content distribution from the hub
+func (w *webSubscriber) handleContent(rw http.ResponseWriter, r *http.Request) {
+	sub, ok := w.lookup(r)
+	if !ok || sub.State != "active" {
+		http.NotFound(rw, r)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, webSubMaxBody))
+	if err != nil {
+		http.Error(rw, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	// per the spec a bad signature is acknowledged but the content is dropped
+	rw.WriteHeader(http.StatusAccepted)
+	if !validHubSignature(r.Header.Get("X-Hub-Signature"), sub.Secret, body) {
+		log.Printf("Dropping WebSub content for %s: invalid signature", sub.TopicUrl)
+		return
+	}
+
+	ctx := context.WithoutCancel(r.Context())
+	feed, err := w.state.DB.GetFeed(ctx, sub.FeedID)
+	if err != nil {
+		log.Printf("Failed to get feed for WebSub content %s: %v", sub.TopicUrl, err)
+		return
+	}
+	parsedFeed, err := parseFeed(body, sub.TopicUrl)
+	if err != nil {
+		log.Printf("Failed to parse WebSub content for %s: %v", feed.Name, err)
+		return
+	}
+	result, err := savePosts(ctx, w.state, feed, parsedFeed.Items, stdScrapeOutput)
+	if err != nil {
+		log.Printf("Failed to save WebSub content for %s: %v", feed.Name, err)
+		return
+	}
+	log.Printf("WebSub push for %s: %d new, %d skipped", feed.Name, result.Saved, result.Skipped)
+}
+
+// validHubSignature checks an X-Hub-Signature header of the form method=hexdigest
+func validHubSignature(header, secret string, body []byte) bool {
+	method, signature, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+	var newHash func() hash.Hash
+	switch method {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
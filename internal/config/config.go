@@ -2,15 +2,61 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/maevlava/Gator/internal/database"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	DBUrl       string `json:"db_url"`
-	CurrentUser string `json:"current_user"`
+	DBUrl          string               `json:"db_url"`
+	CurrentUser    string               `json:"current_user"`
+	CredentialsKey string               `json:"credentials_key,omitempty"`
+	HostLimits     map[string]HostLimit `json:"host_limits,omitempty"`
+	// WebSub push subscriptions are enabled in agg when both are set
+	WebSubCallbackURL string `json:"websub_callback_url,omitempty"`
+	WebSubListenAddr  string `json:"websub_listen_addr,omitempty"`
+	// FetchLogRetention is a duration such as "720h"; fetch history older than this is pruned
+	FetchLogRetention string `json:"fetch_log_retention,omitempty"`
+	// TrackingParams are stripped from post urls; a trailing * matches a prefix. Defaults to DefaultTrackingParams.
+	TrackingParams []string `json:"tracking_params,omitempty"`
+	// Templates are text/template layouts for listings, used by --template NAME.
+	// One named after browse, feeds or following is that command's default text output.
+	Templates map[string]string `json:"templates,omitempty"`
+	// SMTP is the server scheduled digests are mailed through
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+
+	// Set per run by the global --record, --replay, --output and --template flags, never saved
+	RecordDir string `json:"-"`
+	ReplayDir string `json:"-"`
+	Output    string `json:"-"`
+	Template  string `json:"-"`
+}
+
+// HostLimit bounds how hard the aggregator may hit a single host.
+// Keys of Config.HostLimits are domains and also match their subdomains; "*" overrides the default.
+type HostLimit struct {
+	MaxConcurrent int    `json:"max_concurrent,omitempty"`
+	MinInterval   string `json:"min_interval,omitempty"`
+}
+
+// SMTPConfig is an outgoing mail server. Security is "starttls" (the default, and required
+// to succeed), "tls" for implicit TLS as on port 465, or "none" for a local server.
+// Port defaults to 587, 465 or 25 to match. Username turns on PLAIN authentication.
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Security string `json:"security,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from"`
 }
+
+// Used for hosts without a configured limit
+var DefaultHostLimit = HostLimit{MaxConcurrent: 2, MinInterval: "1s"}
+
 type State struct {
 	DB     *database.Queries
 	Config *Config
@@ -21,6 +67,89 @@ func (c *Config) SetUser(username string) error {
 	return write(c)
 }
 
+// FeedCredentialsKey returns the key used to encrypt feed credentials,
+// preferring the GATOR_CREDENTIALS_KEY environment variable over the config file.
+func (c *Config) FeedCredentialsKey() string {
+	if key := os.Getenv("GATOR_CREDENTIALS_KEY"); key != "" {
+		return key
+	}
+	return c.CredentialsKey
+}
+
+// SMTPPassword returns the SMTP password, preferring the GATOR_SMTP_PASSWORD environment variable
+// over the config file.
+func (c *Config) SMTPPassword() string {
+	if password := os.Getenv("GATOR_SMTP_PASSWORD"); password != "" {
+		return password
+	}
+	if c.SMTP == nil {
+		return ""
+	}
+	return c.SMTP.Password
+}
+
+// HostLimitFor returns the limit for host and its minimum interval between requests.
+// The most specific matching domain wins.
+func (c *Config) HostLimitFor(host string) (HostLimit, time.Duration, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	limit, matched := DefaultHostLimit, ""
+	if wildcard, ok := c.HostLimits["*"]; ok {
+		limit = wildcard
+	}
+	for domain, candidate := range c.HostLimits {
+		domain = strings.ToLower(domain)
+		if domain == "*" || len(domain) <= len(matched) {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			limit, matched = candidate, domain
+		}
+	}
+
+	if limit.MaxConcurrent <= 0 {
+		limit.MaxConcurrent = DefaultHostLimit.MaxConcurrent
+	}
+	interval := time.Duration(0)
+	if limit.MinInterval != "" {
+		parsed, err := time.ParseDuration(limit.MinInterval)
+		if err != nil {
+			return HostLimit{}, 0, fmt.Errorf("invalid min_interval '%s' for host %s: %w", limit.MinInterval, host, err)
+		}
+		interval = parsed
+	}
+	return limit, interval, nil
+}
+
+// Used when tracking_params is not set
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid", "igshid",
+	"mc_cid", "mc_eid", "_hsenc", "_hsmi", "mkt_tok", "ref_src", "ref_url",
+}
+
+// TrackingParamList returns the query parameters stripped from post urls
+func (c *Config) TrackingParamList() []string {
+	if c.TrackingParams == nil {
+		return DefaultTrackingParams
+	}
+	return c.TrackingParams
+}
+
+// Used when fetch_log_retention is not set
+const DefaultFetchLogRetention = 30 * 24 * time.Hour
+
+// FetchLogRetentionPeriod returns how long fetch history is kept
+func (c *Config) FetchLogRetentionPeriod() (time.Duration, error) {
+	if c.FetchLogRetention == "" {
+		return DefaultFetchLogRetention, nil
+	}
+	retention, err := time.ParseDuration(c.FetchLogRetention)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fetch_log_retention '%s': %w", c.FetchLogRetention, err)
+	}
+	return retention, nil
+}
+
 func Read() (Config, error) {
 
 	path, err := getConfigFile()
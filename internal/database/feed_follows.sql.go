@@ -85,7 +85,11 @@ func (q *Queries) DeleteFeedFollowForUser(ctx context.Context, arg DeleteFeedFol
 }
 
 const getFollowedFeedsForUser = `-- name: GetFollowedFeedsForUser :many
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id,
+       (SELECT count(*)
+        FROM posts p
+        WHERE (p.feed_id = f.id OR p.id IN (SELECT ps.post_id FROM post_sources ps WHERE ps.feed_id = f.id))
+          AND NOT EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = ff.user_id)) AS unread
 FROM feeds f
          INNER JOIN feed_follows ff ON f.id = ff.feed_id
 WHERE ff.user_id = $1
@@ -98,6 +102,7 @@ type GetFollowedFeedsForUserRow struct {
 	Name      string
 	Url       string
 	UserID    uuid.UUID
+	Unread    int64
 }
 
 func (q *Queries) GetFollowedFeedsForUser(ctx context.Context, userID uuid.UUID) ([]GetFollowedFeedsForUserRow, error) {
@@ -116,6 +121,7 @@ func (q *Queries) GetFollowedFeedsForUser(ctx context.Context, userID uuid.UUID)
 			&i.Name,
 			&i.Url,
 			&i.UserID,
+			&i.Unread,
 		); err != nil {
 			return nil, err
 		}
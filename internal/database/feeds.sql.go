@@ -14,25 +14,43 @@ import (
 )
 
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds(id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds(id, created_at, updated_at, name, url, user_id, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config)
 VALUES (
            $1,
            $2,
            $3,
            $4,
            $5,
-           $6
+           $6,
+           $7,
+           $8,
+           $9,
+           $10,
+           $11,
+           $12,
+           $13,
+           $14,
+           $15
        )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	Url           string
+	UserID        uuid.UUID
+	TlsCaFile     sql.NullString
+	TlsCertFile   sql.NullString
+	TlsKeyFile    sql.NullString
+	TlsServerName sql.NullString
+	AuthType      sql.NullString
+	AuthParam     sql.NullString
+	AuthSecret    []byte
+	FetchFullText bool
+	SearchConfig  string
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -43,6 +61,15 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.TlsCaFile,
+		arg.TlsCertFile,
+		arg.TlsKeyFile,
+		arg.TlsServerName,
+		arg.AuthType,
+		arg.AuthParam,
+		arg.AuthSecret,
+		arg.FetchFullText,
+		arg.SearchConfig,
 	)
 	var i Feed
 	err := row.Scan(
@@ -53,12 +80,21 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.TlsCaFile,
+		&i.TlsCertFile,
+		&i.TlsKeyFile,
+		&i.TlsServerName,
+		&i.AuthType,
+		&i.AuthParam,
+		&i.AuthSecret,
+		&i.FetchFullText,
+		&i.SearchConfig,
 	)
 	return i, err
 }
 
 const getAllFeed = `-- name: GetAllFeed :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config from feeds
 `
 
 func (q *Queries) GetAllFeed(ctx context.Context) ([]Feed, error) {
@@ -78,6 +114,15 @@ func (q *Queries) GetAllFeed(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.TlsCaFile,
+			&i.TlsCertFile,
+			&i.TlsKeyFile,
+			&i.TlsServerName,
+			&i.AuthType,
+			&i.AuthParam,
+			&i.AuthSecret,
+			&i.FetchFullText,
+			&i.SearchConfig,
 		); err != nil {
 			return nil, err
 		}
@@ -140,7 +185,7 @@ func (q *Queries) GetAllFeedsWithUser(ctx context.Context) ([]GetAllFeedsWithUse
 }
 
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds WHERE id = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config from feeds WHERE id = $1
 `
 
 func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -154,12 +199,49 @@ func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.TlsCaFile,
+		&i.TlsCertFile,
+		&i.TlsKeyFile,
+		&i.TlsServerName,
+		&i.AuthType,
+		&i.AuthParam,
+		&i.AuthSecret,
+		&i.FetchFullText,
+		&i.SearchConfig,
+	)
+	return i, err
+}
+
+const getFeedByName = `-- name: GetFeedByName :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config from feeds WHERE name = $1
+`
+
+func (q *Queries) GetFeedByName(ctx context.Context, name string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByName, name)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.TlsCaFile,
+		&i.TlsCertFile,
+		&i.TlsKeyFile,
+		&i.TlsServerName,
+		&i.AuthType,
+		&i.AuthParam,
+		&i.AuthSecret,
+		&i.FetchFullText,
+		&i.SearchConfig,
 	)
 	return i, err
 }
 
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at from feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config from feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
@@ -173,12 +255,68 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.TlsCaFile,
+		&i.TlsCertFile,
+		&i.TlsKeyFile,
+		&i.TlsServerName,
+		&i.AuthType,
+		&i.AuthParam,
+		&i.AuthSecret,
+		&i.FetchFullText,
+		&i.SearchConfig,
 	)
 	return i, err
 }
 
+const getFeedsDueForFetch = `-- name: GetFeedsDueForFetch :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config
+FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at < $1
+ORDER BY last_fetched_at ASC NULLS FIRST
+`
+
+func (q *Queries) GetFeedsDueForFetch(ctx context.Context, lastFetchedAt sql.NullTime) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsDueForFetch, lastFetchedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.TlsCaFile,
+			&i.TlsCertFile,
+			&i.TlsKeyFile,
+			&i.TlsServerName,
+			&i.AuthType,
+			&i.AuthParam,
+			&i.AuthSecret,
+			&i.FetchFullText,
+			&i.SearchConfig,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config
 FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST
 LIMIT 1
@@ -195,10 +333,46 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.TlsCaFile,
+		&i.TlsCertFile,
+		&i.TlsKeyFile,
+		&i.TlsServerName,
+		&i.AuthType,
+		&i.AuthParam,
+		&i.AuthSecret,
+		&i.FetchFullText,
+		&i.SearchConfig,
 	)
 	return i, err
 }
 
+const getSearchConfigs = `-- name: GetSearchConfigs :many
+SELECT DISTINCT search_config FROM feeds ORDER BY search_config
+`
+
+func (q *Queries) GetSearchConfigs(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getSearchConfigs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var search_config string
+		if err := rows.Scan(&search_config); err != nil {
+			return nil, err
+		}
+		items = append(items, search_config)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds
 SET last_fetched_at = $1,
@@ -215,3 +389,39 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.ID)
 	return err
 }
+
+const setFeedFetchFullText = `-- name: SetFeedFetchFullText :exec
+UPDATE feeds
+SET fetch_full_text = $1,
+    updated_at = $2
+WHERE id = $3
+`
+
+type SetFeedFetchFullTextParams struct {
+	FetchFullText bool
+	UpdatedAt     time.Time
+	ID            uuid.UUID
+}
+
+func (q *Queries) SetFeedFetchFullText(ctx context.Context, arg SetFeedFetchFullTextParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFetchFullText, arg.FetchFullText, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setFeedSearchConfig = `-- name: SetFeedSearchConfig :exec
+UPDATE feeds
+SET search_config = $1,
+    updated_at = $2
+WHERE id = $3
+`
+
+type SetFeedSearchConfigParams struct {
+	SearchConfig string
+	UpdatedAt    time.Time
+	ID           uuid.UUID
+}
+
+func (q *Queries) SetFeedSearchConfig(ctx context.Context, arg SetFeedSearchConfigParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSearchConfig, arg.SearchConfig, arg.UpdatedAt, arg.ID)
+	return err
+}
@@ -11,6 +11,22 @@ import (
 	"github.com/google/uuid"
 )
 
+type DigestSchedule struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Email      string
+	Frequency  string
+	SendAt     string
+	Weekday    int32
+	GroupBy    string
+	MarkRead   bool
+	LastSentAt sql.NullTime
+	NextRunAt  time.Time
+	Handle     int64
+}
+
 type Feed struct {
 	ID            uuid.UUID
 	CreatedAt     time.Time
@@ -19,6 +35,15 @@ type Feed struct {
 	Url           string
 	UserID        uuid.UUID
 	LastFetchedAt sql.NullTime
+	TlsCaFile     sql.NullString
+	TlsCertFile   sql.NullString
+	TlsKeyFile    sql.NullString
+	TlsServerName sql.NullString
+	AuthType      sql.NullString
+	AuthParam     sql.NullString
+	AuthSecret    []byte
+	FetchFullText bool
+	SearchConfig  string
 }
 
 type FeedFollow struct {
@@ -29,15 +54,101 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type FetchLog struct {
+	ID           uuid.UUID
+	FeedID       uuid.UUID
+	StartedAt    time.Time
+	DurationMs   int64
+	HttpStatus   sql.NullInt32
+	Bytes        int64
+	NewPosts     int32
+	SkippedPosts int32
+	FailedPosts  int32
+	Error        sql.NullString
+}
+
+type LaterItem struct {
+	UserID   uuid.UUID
+	PostID   uuid.UUID
+	AddedAt  time.Time
+	Position int64
+}
+
 type Post struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Article      sql.NullString
+	Handle       int64
+	Author       string
+	Categories   []string
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostRevision struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	PostID      uuid.UUID
 	Title       string
-	Url         string
 	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	Content     sql.NullString
+	ContentHash string
+	ValidFrom   time.Time
+}
+
+type PostSearch struct {
+	PostID       uuid.UUID
+	SearchVector interface{}
+}
+
+type PostSource struct {
+	PostID    uuid.UUID
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type PostStar struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	StarredAt time.Time
+}
+
+type Rule struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Kind      string
+	Pattern   string
+	Action    string
+	FeedID    uuid.NullUUID
+	Handle    int64
+}
+
+type SavedSearch struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	Query     string
+}
+
+type SavedSearchMatch struct {
+	SavedSearchID uuid.UUID
+	PostID        uuid.UUID
+	MatchedAt     time.Time
 }
 
 type User struct {
@@ -46,3 +157,15 @@ type User struct {
 	UpdatedAt time.Time
 	Name      string
 }
+
+type WebsubSubscription struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FeedID         uuid.UUID
+	HubUrl         string
+	TopicUrl       string
+	Secret         string
+	State          string
+	LeaseExpiresAt sql.NullTime
+}
@@ -11,10 +11,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, author, categories)
 VALUES (
         $1,
         $2,
@@ -23,20 +24,30 @@ VALUES (
         $5,
         $6,
         $7,
-        $8)
+        $8,
+        $9,
+        $10,
+        $11,
+        $12,
+        $13)
 ON CONFLICT (url) DO NOTHING
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, article, handle, author, categories
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Author       string
+	Categories   []string
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -49,7 +60,113 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Content,
+		arg.ContentHash,
+		arg.CanonicalUrl,
+		arg.Author,
+		pq.Array(arg.Categories),
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+		&i.ContentHash,
+		&i.CanonicalUrl,
+		&i.Article,
+		&i.Handle,
+		&i.Author,
+		pq.Array(&i.Categories),
+	)
+	return i, err
+}
+
+const createPostSource = `-- name: CreatePostSource :execrows
+INSERT INTO post_sources (post_id, feed_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (post_id, feed_id) DO NOTHING
+`
+
+type CreatePostSourceParams struct {
+	PostID    uuid.UUID
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreatePostSource(ctx context.Context, arg CreatePostSourceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPostSource, arg.PostID, arg.FeedID, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, article, handle, author, categories FROM posts WHERE id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+		&i.ContentHash,
+		&i.CanonicalUrl,
+		&i.Article,
+		&i.Handle,
+		&i.Author,
+		pq.Array(&i.Categories),
+	)
+	return i, err
+}
+
+const getPostByHandle = `-- name: GetPostByHandle :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, article, handle, author, categories FROM posts WHERE handle = $1
+`
+
+func (q *Queries) GetPostByHandle(ctx context.Context, handle int64) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByHandle, handle)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+		&i.ContentHash,
+		&i.CanonicalUrl,
+		&i.Article,
+		&i.Handle,
+		&i.Author,
+		pq.Array(&i.Categories),
 	)
+	return i, err
+}
+
+const getPostByUrl = `-- name: GetPostByUrl :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, article, handle, author, categories FROM posts WHERE url = $1
+`
+
+func (q *Queries) GetPostByUrl(ctx context.Context, url string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByUrl, url)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -60,31 +177,551 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Content,
+		&i.ContentHash,
+		&i.CanonicalUrl,
+		&i.Article,
+		&i.Handle,
+		&i.Author,
+		pq.Array(&i.Categories),
 	)
 	return i, err
 }
 
+const getPostRevisions = `-- name: GetPostRevisions :many
+SELECT id, created_at, post_id, title, description, content, content_hash, valid_from FROM post_revisions
+WHERE post_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetPostRevisions(ctx context.Context, postID uuid.UUID) ([]PostRevision, error) {
+	rows, err := q.db.QueryContext(ctx, getPostRevisions, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostRevision
+	for rows.Next() {
+		var i PostRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.PostID,
+			&i.Title,
+			&i.Description,
+			&i.Content,
+			&i.ContentHash,
+			&i.ValidFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id FROM posts p INNER JOIN feed_follows ff ON p.feed_id = ff.feed_id
-WHERE ff.user_id = $1
-ORDER BY p.published_at DESC NULLS LAST
-LIMIT $2
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.content_hash, p.canonical_url, p.article, p.handle, p.author, p.categories,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = $1) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = $1) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, $1)::text AS also_in
+FROM listed_posts($1, $2, $3::uuid, $4::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= $5::timestamp
+  AND COALESCE(p.published_at, p.created_at) < $6::timestamp
+  AND (COALESCE(p.published_at, p.created_at), p.id) < ($7::timestamp, $8::uuid)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT $9
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID        uuid.UUID
+	UnreadOnly    bool
+	FeedID        uuid.NullUUID
+	SavedSearchID uuid.NullUUID
+	Since         time.Time
+	Until         time.Time
+	CursorTime    time.Time
+	CursorID      uuid.UUID
+	RowLimit      int32
+}
+
+type GetPostsForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Article      sql.NullString
+	Handle       int64
+	Author       string
+	Categories   []string
+	Updated      bool
+	Read         bool
+	Starred      bool
+	FeedName     string
+	AlsoIn       string
+}
+
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.SavedSearchID,
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserRow
+	for rows.Next() {
+		var i GetPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.ContentHash,
+			&i.CanonicalUrl,
+			&i.Article,
+			&i.Handle,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Updated,
+			&i.Read,
+			&i.Starred,
+			&i.FeedName,
+			&i.AlsoIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserBackwards = `-- name: GetPostsForUserBackwards :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.content_hash, p.canonical_url, p.article, p.handle, p.author, p.categories,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = $1) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = $1) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, $1)::text AS also_in
+FROM listed_posts($1, $2, $3::uuid, $4::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= $5::timestamp
+  AND COALESCE(p.published_at, p.created_at) < $6::timestamp
+  AND (COALESCE(p.published_at, p.created_at), p.id) > ($7::timestamp, $8::uuid)
+ORDER BY COALESCE(p.published_at, p.created_at) ASC, p.id ASC
+LIMIT $9
+`
+
+type GetPostsForUserBackwardsParams struct {
+	UserID        uuid.UUID
+	UnreadOnly    bool
+	FeedID        uuid.NullUUID
+	SavedSearchID uuid.NullUUID
+	Since         time.Time
+	Until         time.Time
+	CursorTime    time.Time
+	CursorID      uuid.UUID
+	RowLimit      int32
+}
+
+type GetPostsForUserBackwardsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Article      sql.NullString
+	Handle       int64
+	Author       string
+	Categories   []string
+	Updated      bool
+	Read         bool
+	Starred      bool
+	FeedName     string
+	AlsoIn       string
+}
+
+func (q *Queries) GetPostsForUserBackwards(ctx context.Context, arg GetPostsForUserBackwardsParams) ([]GetPostsForUserBackwardsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserBackwards,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.SavedSearchID,
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserBackwardsRow
+	for rows.Next() {
+		var i GetPostsForUserBackwardsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.ContentHash,
+			&i.CanonicalUrl,
+			&i.Article,
+			&i.Handle,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Updated,
+			&i.Read,
+			&i.Starred,
+			&i.FeedName,
+			&i.AlsoIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByFeed = `-- name: GetPostsForUserByFeed :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.content_hash, p.canonical_url, p.article, p.handle, p.author, p.categories,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = $1) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = $1) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, $1)::text AS also_in
+FROM listed_posts($1, $2, $3::uuid, $4::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= $5::timestamp
+  AND COALESCE(p.published_at, p.created_at) < $6::timestamp
+  AND COALESCE(f.name > $7::text
+    OR (f.name = $7 AND (COALESCE(p.published_at, p.created_at), p.id) < ($8::timestamp, $9::uuid)), true)
+ORDER BY f.name ASC, COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT $10
+`
+
+type GetPostsForUserByFeedParams struct {
+	UserID        uuid.UUID
+	UnreadOnly    bool
+	FeedID        uuid.NullUUID
+	SavedSearchID uuid.NullUUID
+	Since         time.Time
+	Until         time.Time
+	CursorFeed    sql.NullString
+	CursorTime    time.Time
+	CursorID      uuid.UUID
+	RowLimit      int32
+}
+
+type GetPostsForUserByFeedRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Article      sql.NullString
+	Handle       int64
+	Author       string
+	Categories   []string
+	Updated      bool
+	Read         bool
+	Starred      bool
+	FeedName     string
+	AlsoIn       string
+}
+
+func (q *Queries) GetPostsForUserByFeed(ctx context.Context, arg GetPostsForUserByFeedParams) ([]GetPostsForUserByFeedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFeed,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.SavedSearchID,
+		arg.Since,
+		arg.Until,
+		arg.CursorFeed,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByFeedRow
+	for rows.Next() {
+		var i GetPostsForUserByFeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.ContentHash,
+			&i.CanonicalUrl,
+			&i.Article,
+			&i.Handle,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Updated,
+			&i.Read,
+			&i.Starred,
+			&i.FeedName,
+			&i.AlsoIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByFeedBackwards = `-- name: GetPostsForUserByFeedBackwards :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.content_hash, p.canonical_url, p.article, p.handle, p.author, p.categories,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = $1) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = $1) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, $1)::text AS also_in
+FROM listed_posts($1, $2, $3::uuid, $4::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= $5::timestamp
+  AND COALESCE(p.published_at, p.created_at) < $6::timestamp
+  AND COALESCE(f.name < $7::text
+    OR (f.name = $7 AND (COALESCE(p.published_at, p.created_at), p.id) > ($8::timestamp, $9::uuid)), true)
+ORDER BY f.name DESC, COALESCE(p.published_at, p.created_at) ASC, p.id ASC
+LIMIT $10
+`
+
+type GetPostsForUserByFeedBackwardsParams struct {
+	UserID        uuid.UUID
+	UnreadOnly    bool
+	FeedID        uuid.NullUUID
+	SavedSearchID uuid.NullUUID
+	Since         time.Time
+	Until         time.Time
+	CursorFeed    sql.NullString
+	CursorTime    time.Time
+	CursorID      uuid.UUID
+	RowLimit      int32
+}
+
+type GetPostsForUserByFeedBackwardsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Article      sql.NullString
+	Handle       int64
+	Author       string
+	Categories   []string
+	Updated      bool
+	Read         bool
+	Starred      bool
+	FeedName     string
+	AlsoIn       string
+}
+
+func (q *Queries) GetPostsForUserByFeedBackwards(ctx context.Context, arg GetPostsForUserByFeedBackwardsParams) ([]GetPostsForUserByFeedBackwardsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFeedBackwards,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.SavedSearchID,
+		arg.Since,
+		arg.Until,
+		arg.CursorFeed,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByFeedBackwardsRow
+	for rows.Next() {
+		var i GetPostsForUserByFeedBackwardsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.ContentHash,
+			&i.CanonicalUrl,
+			&i.Article,
+			&i.Handle,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Updated,
+			&i.Read,
+			&i.Starred,
+			&i.FeedName,
+			&i.AlsoIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByFetched = `-- name: GetPostsForUserByFetched :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.content_hash, p.canonical_url, p.article, p.handle, p.author, p.categories,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = $1) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = $1) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, $1)::text AS also_in
+FROM listed_posts($1, $2, $3::uuid, $4::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE p.created_at >= $5::timestamp
+  AND p.created_at < $6::timestamp
+  AND (p.created_at, p.id) < ($7::timestamp, $8::uuid)
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT $9
+`
+
+type GetPostsForUserByFetchedParams struct {
+	UserID        uuid.UUID
+	UnreadOnly    bool
+	FeedID        uuid.NullUUID
+	SavedSearchID uuid.NullUUID
+	Since         time.Time
+	Until         time.Time
+	CursorTime    time.Time
+	CursorID      uuid.UUID
+	RowLimit      int32
+}
+
+type GetPostsForUserByFetchedRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Article      sql.NullString
+	Handle       int64
+	Author       string
+	Categories   []string
+	Updated      bool
+	Read         bool
+	Starred      bool
+	FeedName     string
+	AlsoIn       string
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+func (q *Queries) GetPostsForUserByFetched(ctx context.Context, arg GetPostsForUserByFetchedParams) ([]GetPostsForUserByFetchedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFetched,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.SavedSearchID,
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.RowLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserByFetchedRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserByFetchedRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -94,6 +731,18 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.ContentHash,
+			&i.CanonicalUrl,
+			&i.Article,
+			&i.Handle,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Updated,
+			&i.Read,
+			&i.Starred,
+			&i.FeedName,
+			&i.AlsoIn,
 		); err != nil {
 			return nil, err
 		}
@@ -107,3 +756,293 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getPostsForUserByFetchedBackwards = `-- name: GetPostsForUserByFetchedBackwards :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.content_hash, p.canonical_url, p.article, p.handle, p.author, p.categories,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = $1) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = $1) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, $1)::text AS also_in
+FROM listed_posts($1, $2, $3::uuid, $4::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE p.created_at >= $5::timestamp
+  AND p.created_at < $6::timestamp
+  AND (p.created_at, p.id) > ($7::timestamp, $8::uuid)
+ORDER BY p.created_at ASC, p.id ASC
+LIMIT $9
+`
+
+type GetPostsForUserByFetchedBackwardsParams struct {
+	UserID        uuid.UUID
+	UnreadOnly    bool
+	FeedID        uuid.NullUUID
+	SavedSearchID uuid.NullUUID
+	Since         time.Time
+	Until         time.Time
+	CursorTime    time.Time
+	CursorID      uuid.UUID
+	RowLimit      int32
+}
+
+type GetPostsForUserByFetchedBackwardsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Content      sql.NullString
+	ContentHash  string
+	CanonicalUrl string
+	Article      sql.NullString
+	Handle       int64
+	Author       string
+	Categories   []string
+	Updated      bool
+	Read         bool
+	Starred      bool
+	FeedName     string
+	AlsoIn       string
+}
+
+func (q *Queries) GetPostsForUserByFetchedBackwards(ctx context.Context, arg GetPostsForUserByFetchedBackwardsParams) ([]GetPostsForUserByFetchedBackwardsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFetchedBackwards,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.FeedID,
+		arg.SavedSearchID,
+		arg.Since,
+		arg.Until,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByFetchedBackwardsRow
+	for rows.Next() {
+		var i GetPostsForUserByFetchedBackwardsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.ContentHash,
+			&i.CanonicalUrl,
+			&i.Article,
+			&i.Handle,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Updated,
+			&i.Read,
+			&i.Starred,
+			&i.FeedName,
+			&i.AlsoIn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsWithoutCanonicalUrl = `-- name: GetPostsWithoutCanonicalUrl :many
+SELECT id, url FROM posts
+WHERE canonical_url = ''
+LIMIT $1
+`
+
+type GetPostsWithoutCanonicalUrlRow struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) GetPostsWithoutCanonicalUrl(ctx context.Context, limit int32) ([]GetPostsWithoutCanonicalUrlRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsWithoutCanonicalUrl, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsWithoutCanonicalUrlRow
+	for rows.Next() {
+		var i GetPostsWithoutCanonicalUrlRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reindexPostsForFeed = `-- name: ReindexPostsForFeed :exec
+UPDATE posts
+SET title = title
+WHERE feed_id = $1
+`
+
+func (q *Queries) ReindexPostsForFeed(ctx context.Context, feedID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, reindexPostsForFeed, feedID)
+	return err
+}
+
+const setPostArticle = `-- name: SetPostArticle :exec
+UPDATE posts
+SET article = $1
+WHERE id = $2
+`
+
+type SetPostArticleParams struct {
+	Article sql.NullString
+	ID      uuid.UUID
+}
+
+func (q *Queries) SetPostArticle(ctx context.Context, arg SetPostArticleParams) error {
+	_, err := q.db.ExecContext(ctx, setPostArticle, arg.Article, arg.ID)
+	return err
+}
+
+const setPostCanonicalUrl = `-- name: SetPostCanonicalUrl :exec
+UPDATE posts
+SET canonical_url = $1
+WHERE id = $2
+`
+
+type SetPostCanonicalUrlParams struct {
+	CanonicalUrl string
+	ID           uuid.UUID
+}
+
+func (q *Queries) SetPostCanonicalUrl(ctx context.Context, arg SetPostCanonicalUrlParams) error {
+	_, err := q.db.ExecContext(ctx, setPostCanonicalUrl, arg.CanonicalUrl, arg.ID)
+	return err
+}
+
+const setPostContent = `-- name: SetPostContent :exec
+UPDATE posts
+SET title = $1,
+    description = $2,
+    content = $3,
+    content_hash = $4,
+    published_at = $5,
+    author = $6,
+    categories = $7
+WHERE id = $8
+`
+
+type SetPostContentParams struct {
+	Title       string
+	Description sql.NullString
+	Content     sql.NullString
+	ContentHash string
+	PublishedAt sql.NullTime
+	Author      string
+	Categories  []string
+	ID          uuid.UUID
+}
+
+func (q *Queries) SetPostContent(ctx context.Context, arg SetPostContentParams) error {
+	_, err := q.db.ExecContext(ctx, setPostContent,
+		arg.Title,
+		arg.Description,
+		arg.Content,
+		arg.ContentHash,
+		arg.PublishedAt,
+		arg.Author,
+		pq.Array(arg.Categories),
+		arg.ID,
+	)
+	return err
+}
+
+const updatePostWithRevision = `-- name: UpdatePostWithRevision :one
+WITH old AS (
+    SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, article, handle, author, categories FROM posts WHERE id = $1 FOR UPDATE
+), revision AS (
+    INSERT INTO post_revisions (id, created_at, post_id, title, description, content, content_hash, valid_from)
+    SELECT $2::uuid, $3::timestamp, old.id, old.title, old.description, old.content, old.content_hash, old.updated_at
+    FROM old
+)
+UPDATE posts
+SET title = $4,
+    description = $5,
+    content = $6,
+    content_hash = $7,
+    published_at = $8,
+    author = $9,
+    categories = $10,
+    updated_at = $3::timestamp
+WHERE posts.id = $1
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, article, handle, author, categories
+`
+
+type UpdatePostWithRevisionParams struct {
+	ID          uuid.UUID
+	RevisionID  uuid.UUID
+	UpdatedAt   time.Time
+	Title       string
+	Description sql.NullString
+	Content     sql.NullString
+	ContentHash string
+	PublishedAt sql.NullTime
+	Author      string
+	Categories  []string
+}
+
+func (q *Queries) UpdatePostWithRevision(ctx context.Context, arg UpdatePostWithRevisionParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, updatePostWithRevision,
+		arg.ID,
+		arg.RevisionID,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Description,
+		arg.Content,
+		arg.ContentHash,
+		arg.PublishedAt,
+		arg.Author,
+		pq.Array(arg.Categories),
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+		&i.ContentHash,
+		&i.CanonicalUrl,
+		&i.Article,
+		&i.Handle,
+		&i.Author,
+		pq.Array(&i.Categories),
+	)
+	return i, err
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// upStatements returns the statements of every migration's Up section, keyed by file name
+func upStatements(t *testing.T) map[string][]string {
+	t.Helper()
+	files, err := filepath.Glob("../../sql/schema/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("failed to find migrations: %v", err)
+	}
+	statements := make(map[string][]string)
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(body), "-- +goose Down")
+		statements[filepath.Base(file)] = strings.Split(up, ";")
+	}
+	return statements
+}
+
+var postStarsFKPattern = regexp.MustCompile(`(?is)FOREIGN KEY\s*\(\s*post_id\s*\)\s*REFERENCES\s+posts\s*\(\s*id\s*\)([^,)]*)`)
+
+// Starred posts must survive pruning, so nothing may delete a post out from under a star
+func TestStarredPostsAreNotPruned(t *testing.T) {
+	created := false
+	for file, statements := range upStatements(t) {
+		for _, statement := range statements {
+			normalized := strings.ToUpper(strings.Join(strings.Fields(statement), " "))
+			switch {
+			case strings.Contains(normalized, "CREATE TABLE POST_STARS"):
+				created = true
+				m := postStarsFKPattern.FindStringSubmatch(statement)
+				if m == nil {
+					t.Fatalf("%s: post_stars has no foreign key on post_id", file)
+				}
+				if action := strings.TrimSpace(m[1]); strings.Contains(strings.ToUpper(action), "ON DELETE") {
+					t.Errorf("%s: post_stars.post_id has %s, a starred post must block its deletion", file, action)
+				}
+			case strings.Contains(normalized, "ALTER TABLE POST_STARS"):
+				t.Errorf("%s: post_stars is altered, its post_id foreign key must stay restrictive", file)
+			}
+		}
+	}
+	if !created {
+		t.Fatal("no migration creates post_stars")
+	}
+
+	queries, err := filepath.Glob("../../sql/queries/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range queries {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, statement := range strings.Split(string(body), ";") {
+			normalized := strings.ToUpper(strings.Join(strings.Fields(statement), " "))
+			if strings.Contains(normalized, "DELETE FROM POSTS") && !strings.Contains(normalized, "POST_STARS") {
+				t.Errorf("%s: a query deletes posts without skipping starred ones:\n%s", filepath.Base(file), strings.TrimSpace(statement))
+			}
+		}
+	}
+}
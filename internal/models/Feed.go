@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Feed formats recognised by the parser
+const (
+	FormatRSS2     = "RSS 2.0"
+	FormatRSS1     = "RSS 1.0"
+	FormatAtom     = "Atom"
+	FormatJSONFeed = "JSON Feed"
+)
+
+// ParsedFeed is a feed of any supported format, normalised for the aggregator
+type ParsedFeed struct {
+	Format      string
+	Title       string
+	Link        string
+	Description string
+	Language    string
+	HubURL      string
+	SelfURL     string
+	Items       []ParsedItem
+	Warnings    []string
+}
+
+// ParsedItem is a single post. Published is zero when the item has no usable date,
+// and Warnings lists problems that were worked around while parsing it.
+type ParsedItem struct {
+	Title       string
+	Link        string
+	GUID        string
+	Description string
+	Content     string
+	Author      string
+	Categories  []string
+	Published   time.Time
+	Warnings    []string
+}
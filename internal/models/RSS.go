@@ -1,17 +1,55 @@
 package models
 
+import "encoding/xml"
+
 type RSSFeed struct {
 	Channel struct {
 		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
+		Links       []XMLLink `xml:"link"`
 		Description string    `xml:"description"`
+		Language    string    `xml:"language"`
 		Item        []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string        `xml:"title"`
+	Links       []XMLLink     `xml:"link"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate"`
+	GUID        string        `xml:"guid"`
+	Content     string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string        `xml:"author"`
+	Creator     string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Date        string        `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories  []string      `xml:"category"`
+	Subjects    []string      `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Enclosure   *RSSEnclosure `xml:"enclosure"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// XMLLink matches both plain RSS <link>url</link> and namespaced
+// <atom:link rel="..." href="..."/> elements, which share a local name.
+type XMLLink struct {
+	XMLName xml.Name
+	Href    string `xml:"href,attr"`
+	Rel     string `xml:"rel,attr"`
+	Type    string `xml:"type,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// RDFFeed is an RSS 1.0 document, where items are siblings of the channel
+type RDFFeed struct {
+	Channel struct {
+		Title       string    `xml:"title"`
+		Links       []XMLLink `xml:"link"`
+		Description string    `xml:"description"`
+		Language    string    `xml:"http://purl.org/dc/elements/1.1/ language"`
+	} `xml:"channel"`
+	Item []RSSItem `xml:"item"`
 }
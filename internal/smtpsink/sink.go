@@ -0,0 +1,244 @@
+package smtpsink
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMessageBytes bounds a single message the sink accepts
+const maxMessageBytes = 25 << 20
+
+// Message is a mail the sink received
+type Message struct {
+	From       string
+	To         []string
+	Data       []byte
+	ReceivedAt time.Time
+}
+
+// Sink is a local SMTP server that accepts every message instead of delivering it,
+// for previewing and testing outgoing mail. It speaks plain SMTP without TLS, so
+// senders must not ask for STARTTLS (gator's smtp.security "none"), and it accepts
+// any AUTH PLAIN or LOGIN credentials.
+type Sink struct {
+	listener net.Listener
+	dir      string
+
+	mu       sync.Mutex
+	messages []Message
+	conns    map[net.Conn]bool
+	received chan Message
+
+	wg sync.WaitGroup
+}
+
+// Listen starts a sink on addr, such as 127.0.0.1:2525 or 127.0.0.1:0 for any free port.
+// When dir is set, each message is also saved there as an .eml file.
+func Listen(addr, dir string) (*Sink, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s := &Sink{listener: listener, dir: dir, conns: make(map[net.Conn]bool), received: make(chan Message, 64)}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr is the address the sink listens on
+func (s *Sink) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Messages returns the messages received so far, oldest first
+func (s *Sink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Received delivers messages as they arrive. It is buffered; once the buffer is full,
+// new messages only show up in Messages.
+func (s *Sink) Received() <-chan Message {
+	return s.received
+}
+
+// Close stops the sink, ending any open sessions
+func (s *Sink) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Sink) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.session(conn)
+			conn.Close()
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// session runs one SMTP conversation
+func (s *Sink) session(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	reply := func(format string, args ...any) bool {
+		fmt.Fprintf(writer, format+"\r\n", args...)
+		return writer.Flush() == nil
+	}
+
+	if !reply("220 gator smtp sink ready") {
+		return
+	}
+	var from string
+	var to []string
+	for {
+		conn.SetDeadline(time.Now().Add(5 * time.Minute))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			reply("250-gator smtp sink")
+			reply("250-8BITMIME")
+			reply("250-SIZE %d", maxMessageBytes)
+			reply("250 AUTH PLAIN LOGIN")
+		case "HELO":
+			reply("250 gator smtp sink")
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			switch strings.ToUpper(mechanism) {
+			case "PLAIN":
+				if initial == "" {
+					reply("334 ")
+					if _, err := reader.ReadString('\n'); err != nil {
+						return
+					}
+				}
+			case "LOGIN":
+				// username and password, each asked for in base64
+				for _, prompt := range []string{"VXNlcm5hbWU6", "UGFzc3dvcmQ6"} {
+					reply("334 %s", prompt)
+					if _, err := reader.ReadString('\n'); err != nil {
+						return
+					}
+				}
+			default:
+				reply("504 unrecognized authentication mechanism")
+				continue
+			}
+			reply("235 authentication succeeded")
+		case "MAIL":
+			from = addressIn(arg)
+			to = nil
+			reply("250 ok")
+		case "RCPT":
+			to = append(to, addressIn(arg))
+			reply("250 ok")
+		case "DATA":
+			if len(to) == 0 {
+				reply("503 need RCPT before DATA")
+				continue
+			}
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data, err := readData(reader)
+			if err != nil {
+				reply("552 %v", err)
+				return
+			}
+			s.store(Message{From: from, To: to, Data: data, ReceivedAt: time.Now().UTC()})
+			from, to = "", nil
+			reply("250 ok: queued")
+		case "RSET":
+			from, to = "", nil
+			reply("250 ok")
+		case "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// addressIn extracts the address from a MAIL FROM:<...> or RCPT TO:<...> argument
+func addressIn(arg string) string {
+	_, address, _ := strings.Cut(arg, ":")
+	address, _, _ = strings.Cut(strings.TrimSpace(address), " ")
+	return strings.Trim(address, "<>")
+}
+
+// readData reads a message up to the lone "." line, undoing dot-stuffing
+func readData(reader *bufio.Reader) ([]byte, error) {
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			return data.Bytes(), nil
+		}
+		data.WriteString(strings.TrimPrefix(line, "."))
+		if data.Len() > maxMessageBytes {
+			return nil, errors.New("message too large")
+		}
+	}
+}
+
+func (s *Sink) store(message Message) {
+	s.mu.Lock()
+	s.messages = append(s.messages, message)
+	count := len(s.messages)
+	s.mu.Unlock()
+
+	if s.dir != "" {
+		name := fmt.Sprintf("%s-%03d.eml", message.ReceivedAt.Format("20060102-150405"), count)
+		// a sink that can't save a copy still keeps the message in memory
+		_ = os.WriteFile(filepath.Join(s.dir, name), message.Data, 0o644)
+	}
+
+	select {
+	case s.received <- message:
+	default:
+	}
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	_ "github.com/lib/pq"
 	"github.com/maevlava/Gator/internal/commands"
 	"github.com/maevlava/Gator/internal/config"
 	"github.com/maevlava/Gator/internal/database"
+	"io"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
 )
 
 func main() {
@@ -17,39 +25,94 @@ func main() {
 	}
 	state := &config.State{Config: &cfg}
 	commandsRegistry := &commands.Registry{
-		Commands: make(map[string]func(state *config.State, command commands.CLI) error),
+		Commands: make(map[string]func(ctx context.Context, state *config.State, command commands.CLI) error),
 	}
 	db, err := sql.Open("postgres", state.Config.DBUrl)
 	state.DB = database.New(db)
 
+	// cancelled on Ctrl-C or a service manager stop so long-running commands can wind down
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	registerCommandsHandlers(commandsRegistry)
-	listenToCommands(state, commandsRegistry)
+	listenToCommands(ctx, state, commandsRegistry)
 
 }
 
-func listenToCommands(state *config.State, commandsRegistry *commands.Registry) {
-	args := os.Args
-	if len(args) < 2 {
+func listenToCommands(ctx context.Context, state *config.State, commandsRegistry *commands.Registry) {
+	args, err := parseGlobalFlags(state, os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if len(args) < 1 {
 		fmt.Fprintf(os.Stderr, "not enough arguments\n")
 		os.Exit(1)
 	}
-	command := commands.CLI{Name: args[1], Args: args[2:]}
-	if err := commandsRegistry.Run(state, command); err != nil {
+	command := commands.CLI{Name: args[0], Args: args[1:]}
+	if err := commandsRegistry.Run(ctx, state, command); err != nil {
 		fmt.Fprintf(os.Stderr, "error running command %s: %v\n", command.Name, err)
 		os.Exit(1)
 	}
 }
 
+// parseGlobalFlags handles the flags given before the command name and returns the rest
+func parseGlobalFlags(state *config.State, args []string) ([]string, error) {
+	globals := flag.NewFlagSet("gator", flag.ContinueOnError)
+	globals.SetOutput(io.Discard)
+	record := globals.String("record", "", "record raw feed responses into this archive directory")
+	replay := globals.String("replay", "", "serve feed fetches from this archive directory instead of the network")
+	output := globals.String("output", "text", "format of listings: text, json, jsonl, csv or tsv")
+	template := globals.String("template", "", "Go template, or name of a configured one, to print each listed item with")
+	if err := globals.Parse(args); err != nil {
+		return nil, fmt.Errorf("invalid global flags: %w", err)
+	}
+	if *record != "" && *replay != "" {
+		return nil, errors.New("--record and --replay cannot be used together")
+	}
+	if !slices.Contains(commands.OutputFormats, *output) {
+		return nil, fmt.Errorf("invalid --output '%s': must be %s", *output, strings.Join(commands.OutputFormats, ", "))
+	}
+	if *template != "" && *output != "text" {
+		return nil, errors.New("--template cannot be combined with --output")
+	}
+
+	state.Config.RecordDir = *record
+	state.Config.ReplayDir = *replay
+	state.Config.Output = *output
+	state.Config.Template = *template
+	return globals.Args(), nil
+}
+
 func registerCommandsHandlers(commandsRegistry *commands.Registry) {
 	commandsRegistry.Register("login", commands.LoginHandler)
 	commandsRegistry.Register("register", commands.RegisterHandler)
 	commandsRegistry.Register("reset", commands.ResetHandler)
 	commandsRegistry.Register("users", commands.UserListHandler)
 	commandsRegistry.Register("agg", commands.AggHandler)
+	commandsRegistry.Register("fetch", commands.FetchHandler)
+	commandsRegistry.Register("fetchlog", commands.FetchLogHandler)
+	commandsRegistry.Register("preview", commands.PreviewHandler)
+	commandsRegistry.Register("validate", commands.ValidateHandler)
 	commandsRegistry.Register("feeds", commands.FeedListHandler)
+	commandsRegistry.Register("fulltext", commands.FullTextHandler)
 	commandsRegistry.Register("addfeed", commands.MiddlewareLoggedIn(commands.AddFeedHandler))
 	commandsRegistry.Register("follow", commands.MiddlewareLoggedIn(commands.FollowHandler))
 	commandsRegistry.Register("following", commands.MiddlewareLoggedIn(commands.FollowingHandler))
 	commandsRegistry.Register("unfollow", commands.MiddlewareLoggedIn(commands.UnfollowHandler))
 	commandsRegistry.Register("browse", commands.MiddlewareLoggedIn(commands.BrowseHandler))
+	commandsRegistry.Register("read", commands.MiddlewareLoggedIn(commands.ReadHandler))
+	commandsRegistry.Register("unread", commands.MiddlewareLoggedIn(commands.UnreadHandler))
+	commandsRegistry.Register("catchup", commands.MiddlewareLoggedIn(commands.CatchupHandler))
+	commandsRegistry.Register("star", commands.MiddlewareLoggedIn(commands.StarHandler))
+	commandsRegistry.Register("unstar", commands.MiddlewareLoggedIn(commands.UnstarHandler))
+	commandsRegistry.Register("starred", commands.MiddlewareLoggedIn(commands.StarredHandler))
+	commandsRegistry.Register("later", commands.MiddlewareLoggedIn(commands.LaterHandler))
+	commandsRegistry.Register("search", commands.MiddlewareLoggedIn(commands.SearchHandler))
+	commandsRegistry.Register("savesearch", commands.MiddlewareLoggedIn(commands.SaveSearchHandler))
+	commandsRegistry.Register("rules", commands.MiddlewareLoggedIn(commands.RulesHandler))
+	commandsRegistry.Register("tui", commands.MiddlewareLoggedIn(commands.TUIHandler))
+	commandsRegistry.Register("digest", commands.MiddlewareLoggedIn(commands.DigestHandler))
+	commandsRegistry.Register("mailsink", commands.MailSinkHandler)
+	commandsRegistry.Register("history", commands.HistoryHandler)
 }
@@ -0,0 +1,22 @@
+-- name: CreateDigestSchedule :one
+INSERT INTO digest_schedules (id, created_at, updated_at, user_id, email, frequency, send_at, weekday, group_by, mark_read, next_run_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetDigestSchedulesForUser :many
+SELECT * FROM digest_schedules
+WHERE user_id = $1
+ORDER BY handle;
+
+-- name: GetDueDigestSchedules :many
+SELECT * FROM digest_schedules
+WHERE next_run_at <= $1
+ORDER BY next_run_at;
+
+-- name: MarkDigestScheduleRun :exec
+UPDATE digest_schedules
+SET last_sent_at = $2, next_run_at = $3, updated_at = $4
+WHERE id = $1;
+
+-- name: DeleteDigestSchedule :execrows
+DELETE FROM digest_schedules WHERE user_id = $1 AND handle = $2;
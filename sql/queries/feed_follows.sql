@@ -17,7 +17,11 @@ INNER JOIN feeds ON inserted_feed_follows.feed_id = feeds.id
 INNER JOIN users ON inserted_feed_follows.user_id = users.id;
 
 -- name: GetFollowedFeedsForUser :many
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id,
+       (SELECT count(*)
+        FROM posts p
+        WHERE (p.feed_id = f.id OR p.id IN (SELECT ps.post_id FROM post_sources ps WHERE ps.feed_id = f.id))
+          AND NOT EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = ff.user_id)) AS unread
 FROM feeds f
          INNER JOIN feed_follows ff ON f.id = ff.feed_id
 WHERE ff.user_id = $1;
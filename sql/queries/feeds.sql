@@ -1,12 +1,21 @@
 -- name: CreateFeed :one
-INSERT INTO feeds(id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds(id, created_at, updated_at, name, url, user_id, tls_ca_file, tls_cert_file, tls_key_file, tls_server_name, auth_type, auth_param, auth_secret, fetch_full_text, search_config)
 VALUES (
            $1,
            $2,
            $3,
            $4,
            $5,
-           $6
+           $6,
+           $7,
+           $8,
+           $9,
+           $10,
+           $11,
+           $12,
+           $13,
+           $14,
+           $15
        )
 RETURNING *;
 
@@ -32,7 +41,31 @@ SET last_fetched_at = $1,
 WHERE id = $2;
 
 -- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+SELECT *
 FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetFeedByName :one
+SELECT * from feeds WHERE name = $1;
+
+-- name: GetFeedsDueForFetch :many
+SELECT *
+FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at < $1
+ORDER BY last_fetched_at ASC NULLS FIRST;
+
+-- name: SetFeedFetchFullText :exec
+UPDATE feeds
+SET fetch_full_text = $1,
+    updated_at = $2
+WHERE id = $3;
+
+-- name: SetFeedSearchConfig :exec
+UPDATE feeds
+SET search_config = $1,
+    updated_at = $2
+WHERE id = $3;
+
+-- name: GetSearchConfigs :many
+SELECT DISTINCT search_config FROM feeds ORDER BY search_config;
@@ -0,0 +1,44 @@
+-- name: CreateFetchLog :exec
+INSERT INTO fetch_log(id, feed_id, started_at, duration_ms, http_status, bytes, new_posts, skipped_posts, failed_posts, error)
+VALUES (
+           $1,
+           $2,
+           $3,
+           $4,
+           $5,
+           $6,
+           $7,
+           $8,
+           $9,
+           $10
+       );
+
+-- name: DeleteFetchLogsBefore :execrows
+DELETE FROM fetch_log WHERE started_at < $1;
+
+-- name: GetRecentFetchLogs :many
+SELECT fl.*, f.name AS feed_name
+FROM fetch_log fl
+         INNER JOIN feeds f ON fl.feed_id = f.id
+WHERE sqlc.narg('feed_id')::uuid IS NULL OR fl.feed_id = sqlc.narg('feed_id')::uuid
+ORDER BY fl.started_at DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetFetchLogStats :many
+SELECT f.id AS feed_id,
+       f.name AS feed_name,
+       count(*) AS fetches,
+       count(fl.error) AS failures,
+       COALESCE(sum(fl.new_posts), 0)::bigint AS new_posts
+FROM fetch_log fl
+         INNER JOIN feeds f ON fl.feed_id = f.id
+WHERE sqlc.narg('feed_id')::uuid IS NULL OR fl.feed_id = sqlc.narg('feed_id')::uuid
+GROUP BY f.id, f.name
+ORDER BY f.name;
+
+-- name: GetLastFetchWithNewPosts :one
+SELECT started_at
+FROM fetch_log
+WHERE feed_id = $1 AND new_posts > 0
+ORDER BY started_at DESC
+LIMIT 1;
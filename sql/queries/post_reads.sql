@@ -0,0 +1,29 @@
+-- name: MarkPostRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT sqlc.arg('user_id')::uuid, p.id, sqlc.arg('read_at')::timestamp
+FROM posts p
+WHERE p.id = sqlc.arg('post_id')
+   OR (p.canonical_url <> '' AND p.canonical_url = (SELECT canonical_url FROM posts WHERE id = sqlc.arg('post_id')))
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = sqlc.arg('user_id')
+  AND post_id IN (SELECT p.id
+                  FROM posts p
+                  WHERE p.id = sqlc.arg('post_id')
+                     OR (p.canonical_url <> '' AND p.canonical_url = (SELECT canonical_url FROM posts WHERE id = sqlc.arg('post_id'))));
+
+-- name: MarkPostsReadForUser :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT sqlc.arg('user_id')::uuid, p.id, sqlc.arg('read_at')::timestamp
+FROM posts p
+WHERE (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = sqlc.arg('user_id'))
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = sqlc.arg('user_id')))
+  AND (sqlc.narg('feed_id')::uuid IS NULL
+    OR p.feed_id = sqlc.narg('feed_id')
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps WHERE ps.feed_id = sqlc.narg('feed_id')))
+  AND (sqlc.narg('saved_search_id')::uuid IS NULL
+    OR p.id IN (SELECT m.post_id FROM saved_search_matches m WHERE m.saved_search_id = sqlc.narg('saved_search_id')))
+  AND (sqlc.narg('before')::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < sqlc.narg('before'))
+ON CONFLICT (user_id, post_id) DO NOTHING;
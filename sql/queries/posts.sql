@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, content_hash, canonical_url, author, categories)
 VALUES (
         $1,
         $2,
@@ -8,12 +8,173 @@ VALUES (
         $5,
         $6,
         $7,
-        $8)
+        $8,
+        $9,
+        $10,
+        $11,
+        $12,
+        $13)
 ON CONFLICT (url) DO NOTHING
 RETURNING *;
 
 -- name: GetPostsForUser :many
-SELECT p.* FROM posts p INNER JOIN feed_follows ff ON p.feed_id = ff.feed_id
-WHERE ff.user_id = $1
-ORDER BY p.published_at DESC NULLS LAST
-LIMIT $2;
\ No newline at end of file
+SELECT p.*,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = sqlc.arg('user_id')) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = sqlc.arg('user_id')) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, sqlc.arg('user_id'))::text AS also_in
+FROM listed_posts(sqlc.arg('user_id'), sqlc.arg('unread_only'), sqlc.narg('feed_id')::uuid, sqlc.narg('saved_search_id')::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= sqlc.arg('since')::timestamp
+  AND COALESCE(p.published_at, p.created_at) < sqlc.arg('until')::timestamp
+  AND (COALESCE(p.published_at, p.created_at), p.id) < (sqlc.arg('cursor_time')::timestamp, sqlc.arg('cursor_id')::uuid)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetPostsForUserBackwards :many
+SELECT p.*,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = sqlc.arg('user_id')) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = sqlc.arg('user_id')) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, sqlc.arg('user_id'))::text AS also_in
+FROM listed_posts(sqlc.arg('user_id'), sqlc.arg('unread_only'), sqlc.narg('feed_id')::uuid, sqlc.narg('saved_search_id')::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= sqlc.arg('since')::timestamp
+  AND COALESCE(p.published_at, p.created_at) < sqlc.arg('until')::timestamp
+  AND (COALESCE(p.published_at, p.created_at), p.id) > (sqlc.arg('cursor_time')::timestamp, sqlc.arg('cursor_id')::uuid)
+ORDER BY COALESCE(p.published_at, p.created_at) ASC, p.id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetPostsForUserByFetched :many
+SELECT p.*,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = sqlc.arg('user_id')) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = sqlc.arg('user_id')) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, sqlc.arg('user_id'))::text AS also_in
+FROM listed_posts(sqlc.arg('user_id'), sqlc.arg('unread_only'), sqlc.narg('feed_id')::uuid, sqlc.narg('saved_search_id')::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE p.created_at >= sqlc.arg('since')::timestamp
+  AND p.created_at < sqlc.arg('until')::timestamp
+  AND (p.created_at, p.id) < (sqlc.arg('cursor_time')::timestamp, sqlc.arg('cursor_id')::uuid)
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetPostsForUserByFetchedBackwards :many
+SELECT p.*,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = sqlc.arg('user_id')) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = sqlc.arg('user_id')) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, sqlc.arg('user_id'))::text AS also_in
+FROM listed_posts(sqlc.arg('user_id'), sqlc.arg('unread_only'), sqlc.narg('feed_id')::uuid, sqlc.narg('saved_search_id')::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE p.created_at >= sqlc.arg('since')::timestamp
+  AND p.created_at < sqlc.arg('until')::timestamp
+  AND (p.created_at, p.id) > (sqlc.arg('cursor_time')::timestamp, sqlc.arg('cursor_id')::uuid)
+ORDER BY p.created_at ASC, p.id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetPostsForUserByFeed :many
+SELECT p.*,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = sqlc.arg('user_id')) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = sqlc.arg('user_id')) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, sqlc.arg('user_id'))::text AS also_in
+FROM listed_posts(sqlc.arg('user_id'), sqlc.arg('unread_only'), sqlc.narg('feed_id')::uuid, sqlc.narg('saved_search_id')::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= sqlc.arg('since')::timestamp
+  AND COALESCE(p.published_at, p.created_at) < sqlc.arg('until')::timestamp
+  AND COALESCE(f.name > sqlc.narg('cursor_feed')::text
+    OR (f.name = sqlc.narg('cursor_feed') AND (COALESCE(p.published_at, p.created_at), p.id) < (sqlc.arg('cursor_time')::timestamp, sqlc.arg('cursor_id')::uuid)), true)
+ORDER BY f.name ASC, COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetPostsForUserByFeedBackwards :many
+SELECT p.*,
+       EXISTS(SELECT 1 FROM post_revisions r WHERE r.post_id = p.id) AS updated,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = sqlc.arg('user_id')) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = sqlc.arg('user_id')) AS starred,
+       f.name AS feed_name,
+       post_also_in(p, sqlc.arg('user_id'))::text AS also_in
+FROM listed_posts(sqlc.arg('user_id'), sqlc.arg('unread_only'), sqlc.narg('feed_id')::uuid, sqlc.narg('saved_search_id')::uuid) p
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE COALESCE(p.published_at, p.created_at) >= sqlc.arg('since')::timestamp
+  AND COALESCE(p.published_at, p.created_at) < sqlc.arg('until')::timestamp
+  AND COALESCE(f.name < sqlc.narg('cursor_feed')::text
+    OR (f.name = sqlc.narg('cursor_feed') AND (COALESCE(p.published_at, p.created_at), p.id) > (sqlc.arg('cursor_time')::timestamp, sqlc.arg('cursor_id')::uuid)), true)
+ORDER BY f.name DESC, COALESCE(p.published_at, p.created_at) ASC, p.id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetPost :one
+SELECT * FROM posts WHERE id = $1;
+
+-- name: GetPostByHandle :one
+SELECT * FROM posts WHERE handle = $1;
+
+-- name: GetPostByUrl :one
+SELECT * FROM posts WHERE url = $1;
+
+-- name: UpdatePostWithRevision :one
+WITH old AS (
+    SELECT * FROM posts WHERE id = sqlc.arg('id') FOR UPDATE
+), revision AS (
+    INSERT INTO post_revisions (id, created_at, post_id, title, description, content, content_hash, valid_from)
+    SELECT sqlc.arg('revision_id')::uuid, sqlc.arg('updated_at')::timestamp, old.id, old.title, old.description, old.content, old.content_hash, old.updated_at
+    FROM old
+)
+UPDATE posts
+SET title = sqlc.arg('title'),
+    description = sqlc.arg('description'),
+    content = sqlc.arg('content'),
+    content_hash = sqlc.arg('content_hash'),
+    published_at = sqlc.arg('published_at'),
+    author = sqlc.arg('author'),
+    categories = sqlc.arg('categories'),
+    updated_at = sqlc.arg('updated_at')::timestamp
+WHERE posts.id = sqlc.arg('id')
+RETURNING *;
+
+-- name: SetPostContent :exec
+UPDATE posts
+SET title = $1,
+    description = $2,
+    content = $3,
+    content_hash = $4,
+    published_at = $5,
+    author = $6,
+    categories = $7
+WHERE id = $8;
+
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_id = $1
+ORDER BY created_at ASC;
+
+-- name: CreatePostSource :execrows
+INSERT INTO post_sources (post_id, feed_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (post_id, feed_id) DO NOTHING;
+
+-- name: GetPostsWithoutCanonicalUrl :many
+SELECT id, url FROM posts
+WHERE canonical_url = ''
+LIMIT $1;
+
+-- name: SetPostCanonicalUrl :exec
+UPDATE posts
+SET canonical_url = $1
+WHERE id = $2;
+
+-- name: SetPostArticle :exec
+UPDATE posts
+SET article = $1
+WHERE id = $2;
+
+-- name: ReindexPostsForFeed :exec
+UPDATE posts
+SET title = title
+WHERE feed_id = $1;
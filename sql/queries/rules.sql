@@ -0,0 +1,14 @@
+-- name: CreateRule :one
+INSERT INTO rules (id, created_at, user_id, kind, pattern, action, feed_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetRulesForUser :many
+SELECT r.*, COALESCE(f.name, '')::text AS feed_name
+FROM rules r
+         LEFT JOIN feeds f ON r.feed_id = f.id
+WHERE r.user_id = $1
+ORDER BY r.handle;
+
+-- name: DeleteRule :execrows
+DELETE FROM rules WHERE user_id = $1 AND handle = $2;
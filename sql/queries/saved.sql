@@ -0,0 +1,36 @@
+-- name: StarPost :exec
+INSERT INTO post_stars (user_id, post_id, starred_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnstarPost :execrows
+DELETE FROM post_stars
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetStarredPostsForUser :many
+SELECT p.*, s.starred_at, f.name AS feed_name,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = s.user_id) AS read
+FROM post_stars s
+         INNER JOIN posts p ON s.post_id = p.id
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE s.user_id = $1
+ORDER BY s.starred_at DESC;
+
+-- name: AddLaterItem :exec
+INSERT INTO later_items (user_id, post_id, added_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: RemoveLaterItem :execrows
+DELETE FROM later_items
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetLaterItemsForUser :many
+SELECT p.*, l.added_at, f.name AS feed_name,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = l.user_id) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = l.user_id) AS starred
+FROM later_items l
+         INNER JOIN posts p ON l.post_id = p.id
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE l.user_id = $1
+ORDER BY l.position ASC;
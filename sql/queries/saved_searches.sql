@@ -0,0 +1,46 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (id, created_at, user_id, name, query)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSavedSearchByName :one
+SELECT * FROM saved_searches WHERE user_id = $1 AND name = $2;
+
+-- name: GetSavedSearchesForUser :many
+SELECT ss.*,
+       (SELECT count(*) FROM listed_posts(ss.user_id, true, NULL, ss.id)) AS unread
+FROM saved_searches ss
+WHERE ss.user_id = $1
+ORDER BY ss.name;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches WHERE user_id = $1 AND name = $2;
+
+-- name: MatchSavedSearchesForPost :exec
+INSERT INTO saved_search_matches (saved_search_id, post_id, matched_at)
+SELECT ss.id, p.id, sqlc.arg('matched_at')::timestamp
+FROM posts p
+         INNER JOIN feeds f ON p.feed_id = f.id
+         INNER JOIN post_search s ON s.post_id = p.id
+         CROSS JOIN saved_searches ss
+WHERE p.id = sqlc.arg('post_id')
+  AND (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = ss.user_id)
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = ss.user_id))
+  AND s.search_vector @@ websearch_to_tsquery(f.search_config::regconfig, ss.query)
+ON CONFLICT (saved_search_id, post_id) DO NOTHING;
+
+-- name: DeleteSavedSearchMatchesForPost :exec
+DELETE FROM saved_search_matches WHERE post_id = $1;
+
+-- name: MatchPostsForSavedSearch :execrows
+INSERT INTO saved_search_matches (saved_search_id, post_id, matched_at)
+SELECT ss.id, p.id, sqlc.arg('matched_at')::timestamp
+FROM saved_searches ss
+         CROSS JOIN posts p
+         INNER JOIN feeds f ON p.feed_id = f.id
+         INNER JOIN post_search s ON s.post_id = p.id
+WHERE ss.id = sqlc.arg('saved_search_id')
+  AND (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = ss.user_id)
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = ss.user_id))
+  AND s.search_vector @@ websearch_to_tsquery(f.search_config::regconfig, ss.query)
+ON CONFLICT (saved_search_id, post_id) DO NOTHING;
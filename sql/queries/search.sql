@@ -0,0 +1,23 @@
+-- name: SearchPostsForUser :many
+SELECT p.*,
+       f.name AS feed_name,
+       EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = sqlc.arg('user_id')) AS read,
+       EXISTS(SELECT 1 FROM post_stars st WHERE st.post_id = p.id AND st.user_id = sqlc.arg('user_id')) AS starred,
+       ts_rank(s.search_vector, websearch_to_tsquery(sqlc.arg('search_config')::text::regconfig, sqlc.arg('query')::text)) AS rank,
+       ts_headline(sqlc.arg('search_config')::text::regconfig,
+                   regexp_replace(COALESCE(p.article, p.content, p.description, ''), '<[^>]*>', ' ', 'g'),
+                   websearch_to_tsquery(sqlc.arg('search_config')::text::regconfig, sqlc.arg('query')::text),
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=8')::text AS snippet
+FROM post_search s
+         INNER JOIN posts p ON s.post_id = p.id
+         INNER JOIN feeds f ON p.feed_id = f.id
+WHERE f.search_config = sqlc.arg('search_config')::text
+  AND s.search_vector @@ websearch_to_tsquery(sqlc.arg('search_config')::text::regconfig, sqlc.arg('query')::text)
+  AND (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = sqlc.arg('user_id'))
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = sqlc.arg('user_id')))
+  AND (sqlc.narg('feed_id')::uuid IS NULL
+    OR p.feed_id = sqlc.narg('feed_id')
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps WHERE ps.feed_id = sqlc.narg('feed_id')))
+  AND (sqlc.narg('since')::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) >= sqlc.narg('since'))
+ORDER BY rank DESC, COALESCE(p.published_at, p.created_at) DESC
+LIMIT sqlc.arg('row_limit');
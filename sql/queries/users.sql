@@ -15,4 +15,7 @@ SELECT id, created_at, updated_at, name FROM users WHERE name = $1;
 DELETE FROM users;
 
 -- name: GetAllUsers :many
-SELECT * FROM users;
\ No newline at end of file
+SELECT * FROM users;
+
+-- name: GetUserByID :one
+SELECT id, created_at, updated_at, name FROM users WHERE id = $1;
@@ -0,0 +1,49 @@
+-- name: UpsertWebsubSubscription :one
+INSERT INTO websub_subscriptions(id, created_at, updated_at, feed_id, hub_url, topic_url, secret, state)
+VALUES (
+           $1,
+           $2,
+           $3,
+           $4,
+           $5,
+           $6,
+           $7,
+           'pending'
+       )
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    hub_url = EXCLUDED.hub_url,
+    topic_url = EXCLUDED.topic_url,
+    secret = EXCLUDED.secret,
+    state = 'pending'
+RETURNING *;
+
+-- name: GetWebsubSubscription :one
+SELECT * FROM websub_subscriptions WHERE id = $1;
+
+-- name: GetWebsubSubscriptionForFeed :one
+SELECT * FROM websub_subscriptions WHERE feed_id = $1;
+
+-- name: ActivateWebsubSubscription :exec
+UPDATE websub_subscriptions
+SET state = 'active',
+    lease_expires_at = $1,
+    updated_at = $2
+WHERE id = $3;
+
+-- name: SetWebsubSubscriptionState :exec
+UPDATE websub_subscriptions
+SET state = $1,
+    updated_at = $2
+WHERE id = $3;
+
+-- name: GetWebsubSubscriptionsToRenew :many
+SELECT *
+FROM websub_subscriptions
+WHERE state = 'active' AND lease_expires_at < $1 AND updated_at < $2
+ORDER BY lease_expires_at ASC;
+
+-- name: TouchWebsubSubscription :exec
+UPDATE websub_subscriptions
+SET updated_at = $1
+WHERE id = $2;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Optional per-feed TLS settings for feeds served behind a private CA or mutual TLS
+ALTER TABLE feeds
+    ADD COLUMN tls_ca_file     TEXT NULL,
+    ADD COLUMN tls_cert_file   TEXT NULL,
+    ADD COLUMN tls_key_file    TEXT NULL,
+    ADD COLUMN tls_server_name TEXT NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+    DROP COLUMN tls_ca_file,
+    DROP COLUMN tls_cert_file,
+    DROP COLUMN tls_key_file,
+    DROP COLUMN tls_server_name;
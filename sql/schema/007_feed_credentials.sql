@@ -0,0 +1,12 @@
+-- +goose Up
+-- Credentials for private feeds; auth_secret is encrypted by the client before it is stored
+ALTER TABLE feeds
+    ADD COLUMN auth_type   TEXT  NULL,
+    ADD COLUMN auth_param  TEXT  NULL,
+    ADD COLUMN auth_secret BYTEA NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+    DROP COLUMN auth_type,
+    DROP COLUMN auth_param,
+    DROP COLUMN auth_secret;
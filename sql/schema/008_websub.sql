@@ -0,0 +1,19 @@
+-- +goose Up
+-- WebSub (PubSubHubbub) subscriptions, one per feed that advertises a hub
+CREATE TABLE websub_subscriptions
+(
+    id               UUID PRIMARY KEY,
+    created_at       TIMESTAMP NOT NULL,
+    updated_at       TIMESTAMP NOT NULL,
+    feed_id          UUID UNIQUE NOT NULL,
+    hub_url          TEXT      NOT NULL,
+    topic_url        TEXT      NOT NULL,
+    secret           TEXT      NOT NULL,
+    state            TEXT      NOT NULL,
+    lease_expires_at TIMESTAMP NULL,
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE websub_subscriptions;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- One row per feed fetch, pruned after the configured retention period
+CREATE TABLE fetch_log
+(
+    id            UUID PRIMARY KEY,
+    feed_id       UUID      NOT NULL,
+    started_at    TIMESTAMP NOT NULL,
+    duration_ms   BIGINT    NOT NULL,
+    http_status   INTEGER   NULL,
+    bytes         BIGINT    NOT NULL,
+    new_posts     INTEGER   NOT NULL,
+    skipped_posts INTEGER   NOT NULL,
+    failed_posts  INTEGER   NOT NULL,
+    error         TEXT      NULL,
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+CREATE INDEX fetch_log_feed_started_idx ON fetch_log (feed_id, started_at DESC);
+CREATE INDEX fetch_log_started_idx ON fetch_log (started_at);
+
+-- +goose Down
+DROP TABLE fetch_log;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Keep the body of posts and a hash of what was stored so corrected posts can be detected
+ALTER TABLE posts
+    ADD COLUMN content      TEXT NULL,
+    ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+
+-- Superseded versions of posts; valid_from..created_at is when each version was current
+CREATE TABLE post_revisions
+(
+    id           UUID PRIMARY KEY,
+    created_at   TIMESTAMP NOT NULL,
+    post_id      UUID      NOT NULL,
+    title        TEXT      NOT NULL,
+    description  TEXT      NULL,
+    content      TEXT      NULL,
+    content_hash TEXT      NOT NULL,
+    valid_from   TIMESTAMP NOT NULL,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX post_revisions_post_idx ON post_revisions (post_id, created_at);
+
+-- +goose Down
+DROP TABLE post_revisions;
+ALTER TABLE posts
+    DROP COLUMN content,
+    DROP COLUMN content_hash;
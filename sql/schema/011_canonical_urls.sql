@@ -0,0 +1,22 @@
+-- +goose Up
+-- canonical_url ignores scheme, www/amp variants and trailing slashes so duplicates across feeds can be grouped
+ALTER TABLE posts ADD COLUMN canonical_url TEXT NOT NULL DEFAULT '';
+CREATE INDEX posts_canonical_url_idx ON posts (canonical_url);
+
+-- Other feeds that carried a post stored under a different feed
+CREATE TABLE post_sources
+(
+    post_id    UUID      NOT NULL,
+    feed_id    UUID      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (post_id, feed_id),
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_sources;
+DROP INDEX posts_canonical_url_idx;
+ALTER TABLE posts DROP COLUMN canonical_url;
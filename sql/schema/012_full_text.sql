@@ -0,0 +1,8 @@
+-- +goose Up
+-- Feeds that only publish summaries can opt in to having each new post's page extracted
+ALTER TABLE feeds ADD COLUMN fetch_full_text BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE posts ADD COLUMN article TEXT NULL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN article;
+ALTER TABLE feeds DROP COLUMN fetch_full_text;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- post_id deliberately has no ON DELETE CASCADE: a starred post can't be pruned
+CREATE TABLE post_stars
+(
+    user_id    UUID      NOT NULL,
+    post_id    UUID      NOT NULL,
+    starred_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id)
+    REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+);
+
+-- Read-later queue, worked through in the order posts were added
+CREATE TABLE later_items
+(
+    user_id  UUID      NOT NULL,
+    post_id  UUID      NOT NULL,
+    added_at TIMESTAMP NOT NULL,
+    position BIGSERIAL NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id)
+    REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id) ON DELETE CASCADE
+);
+CREATE INDEX later_items_user_position_idx ON later_items (user_id, position);
+
+-- +goose Down
+DROP TABLE later_items;
+DROP TABLE post_stars;
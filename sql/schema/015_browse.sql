@@ -0,0 +1,48 @@
+-- +goose Up
+-- browse pages through posts newest first on (published_at, id), undated posts by when they were first seen
+CREATE INDEX posts_sort_time_id_idx ON posts ((COALESCE(published_at, created_at)) DESC, id DESC);
+CREATE INDEX post_sources_feed_id_idx ON post_sources (feed_id);
+
+-- listed_posts is every post a user's browse lists: posts of followed feeds or carried by them,
+-- unread ones only when asked, with a story other followed feeds carried under another url
+-- listed once, as the post seen first. Plain SQL and STABLE so the planner inlines it and each
+-- query's ORDER BY still walks its index.
+-- +goose StatementBegin
+CREATE FUNCTION listed_posts(for_user UUID, only_unread BOOLEAN) RETURNS SETOF posts
+    LANGUAGE sql STABLE AS
+$$
+SELECT p.*
+FROM posts p
+WHERE (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = for_user)
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = for_user))
+  AND (NOT only_unread
+    OR NOT EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = for_user))
+  AND (p.canonical_url = ''
+    OR NOT EXISTS(SELECT 1
+                  FROM posts d INNER JOIN feed_follows dff ON d.feed_id = dff.feed_id
+                  WHERE dff.user_id = for_user
+                    AND d.canonical_url = p.canonical_url AND d.feed_id <> p.feed_id
+                    AND (d.created_at, d.id) < (p.created_at, p.id)))
+$$;
+-- +goose StatementEnd
+
+-- post_also_in names the other feeds that carried a post, by source or under its canonical url
+-- +goose StatementBegin
+CREATE FUNCTION post_also_in(post posts, for_user UUID) RETURNS TEXT
+    LANGUAGE sql STABLE AS
+$$
+SELECT COALESCE((SELECT string_agg(sf.name, ', ' ORDER BY sf.name)
+                 FROM feeds sf
+                 WHERE sf.id IN (SELECT ps.feed_id FROM post_sources ps WHERE ps.post_id = post.id)
+                    OR (post.canonical_url <> '' AND sf.id <> post.feed_id
+                        AND sf.id IN (SELECT d.feed_id
+                                      FROM posts d INNER JOIN feed_follows dff ON d.feed_id = dff.feed_id
+                                      WHERE dff.user_id = for_user AND d.canonical_url = post.canonical_url))), '')
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION post_also_in(posts, UUID);
+DROP FUNCTION listed_posts(UUID, BOOLEAN);
+DROP INDEX post_sources_feed_id_idx;
+DROP INDEX posts_sort_time_id_idx;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- browse also pages through one feed, and through all posts by when they were fetched
+CREATE INDEX posts_feed_sort_time_id_idx ON posts (feed_id, (COALESCE(published_at, created_at)) DESC, id DESC);
+CREATE INDEX posts_created_at_id_idx ON posts (created_at DESC, id DESC);
+
+-- listed_posts can now be narrowed to the posts a feed published or carried, which are all listed
+-- since the user asked for that feed's own copy of a story
+DROP FUNCTION listed_posts(UUID, BOOLEAN);
+-- +goose StatementBegin
+CREATE FUNCTION listed_posts(for_user UUID, only_unread BOOLEAN, in_feed UUID) RETURNS SETOF posts
+    LANGUAGE sql STABLE AS
+$$
+SELECT p.*
+FROM posts p
+WHERE (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = for_user)
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = for_user))
+  AND (NOT only_unread
+    OR NOT EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = for_user))
+  AND (in_feed IS NULL
+    OR p.feed_id = in_feed
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps WHERE ps.feed_id = in_feed))
+  AND (in_feed IS NOT NULL OR p.canonical_url = ''
+    OR NOT EXISTS(SELECT 1
+                  FROM posts d INNER JOIN feed_follows dff ON d.feed_id = dff.feed_id
+                  WHERE dff.user_id = for_user
+                    AND d.canonical_url = p.canonical_url AND d.feed_id <> p.feed_id
+                    AND (d.created_at, d.id) < (p.created_at, p.id)))
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION listed_posts(UUID, BOOLEAN, UUID);
+-- +goose StatementBegin
+CREATE FUNCTION listed_posts(for_user UUID, only_unread BOOLEAN) RETURNS SETOF posts
+    LANGUAGE sql STABLE AS
+$$
+SELECT p.*
+FROM posts p
+WHERE (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = for_user)
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = for_user))
+  AND (NOT only_unread
+    OR NOT EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = for_user))
+  AND (p.canonical_url = ''
+    OR NOT EXISTS(SELECT 1
+                  FROM posts d INNER JOIN feed_follows dff ON d.feed_id = dff.feed_id
+                  WHERE dff.user_id = for_user
+                    AND d.canonical_url = p.canonical_url AND d.feed_id <> p.feed_id
+                    AND (d.created_at, d.id) < (p.created_at, p.id)))
+$$;
+-- +goose StatementEnd
+DROP INDEX posts_created_at_id_idx;
+DROP INDEX posts_feed_sort_time_id_idx;
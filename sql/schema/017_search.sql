@@ -0,0 +1,44 @@
+-- +goose Up
+-- Text search configuration (english, german, ... or simple) used to stem a feed's posts
+ALTER TABLE feeds ADD COLUMN search_config TEXT NOT NULL DEFAULT 'simple';
+
+-- Kept out of posts so SELECT * never drags the vectors along
+CREATE TABLE post_search
+(
+    post_id       UUID     NOT NULL PRIMARY KEY,
+    search_vector TSVECTOR NOT NULL,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id) ON DELETE CASCADE
+);
+CREATE INDEX post_search_vector_idx ON post_search USING GIN (search_vector);
+
+-- +goose StatementBegin
+CREATE FUNCTION post_search_update() RETURNS trigger AS $$
+DECLARE
+    cfg regconfig;
+BEGIN
+    SELECT search_config::regconfig INTO cfg FROM feeds WHERE id = NEW.feed_id;
+    cfg := COALESCE(cfg, 'simple'::regconfig);
+    INSERT INTO post_search (post_id, search_vector)
+    VALUES (NEW.id,
+            setweight(to_tsvector(cfg, NEW.title), 'A') ||
+            setweight(to_tsvector(cfg, regexp_replace(COALESCE(NEW.description, ''), '<[^>]*>', ' ', 'g')), 'B') ||
+            setweight(to_tsvector(cfg, regexp_replace(COALESCE(NEW.article, NEW.content, ''), '<[^>]*>', ' ', 'g')), 'C'))
+    ON CONFLICT (post_id) DO UPDATE SET search_vector = EXCLUDED.search_vector;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER post_search_update
+    AFTER INSERT OR UPDATE OF title, description, content, article, feed_id ON posts
+    FOR EACH ROW EXECUTE FUNCTION post_search_update();
+
+-- index what is already stored
+UPDATE posts SET title = title;
+
+-- +goose Down
+DROP TRIGGER post_search_update ON posts;
+DROP FUNCTION post_search_update();
+DROP TABLE post_search;
+ALTER TABLE feeds DROP COLUMN search_config;
@@ -0,0 +1,81 @@
+-- +goose Up
+-- Saved searches behave like per-user virtual feeds of the posts matching their query
+CREATE TABLE saved_searches
+(
+    id         UUID      PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id    UUID      NOT NULL,
+    name       TEXT      NOT NULL,
+    query      TEXT      NOT NULL,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id)
+    REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Filled as posts are stored, so a saved search never re-runs its query when browsed
+CREATE TABLE saved_search_matches
+(
+    saved_search_id UUID      NOT NULL,
+    post_id         UUID      NOT NULL,
+    matched_at      TIMESTAMP NOT NULL,
+    PRIMARY KEY (saved_search_id, post_id),
+    FOREIGN KEY (saved_search_id)
+    REFERENCES saved_searches(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- listed_posts can now be narrowed to a saved search's matches; a story is then listed as the
+-- first post of it that matched
+DROP FUNCTION listed_posts(UUID, BOOLEAN, UUID);
+-- +goose StatementBegin
+CREATE FUNCTION listed_posts(for_user UUID, only_unread BOOLEAN, in_feed UUID, in_saved_search UUID) RETURNS SETOF posts
+    LANGUAGE sql STABLE AS
+$$
+SELECT p.*
+FROM posts p
+WHERE (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = for_user)
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = for_user))
+  AND (NOT only_unread
+    OR NOT EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = for_user))
+  AND (in_feed IS NULL
+    OR p.feed_id = in_feed
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps WHERE ps.feed_id = in_feed))
+  AND (in_saved_search IS NULL
+    OR p.id IN (SELECT m.post_id FROM saved_search_matches m WHERE m.saved_search_id = in_saved_search))
+  AND (in_feed IS NOT NULL OR p.canonical_url = ''
+    OR NOT EXISTS(SELECT 1
+                  FROM posts d INNER JOIN feed_follows dff ON d.feed_id = dff.feed_id
+                  WHERE dff.user_id = for_user
+                    AND d.canonical_url = p.canonical_url AND d.feed_id <> p.feed_id
+                    AND (d.created_at, d.id) < (p.created_at, p.id)
+                    AND (in_saved_search IS NULL
+                      OR d.id IN (SELECT m.post_id FROM saved_search_matches m WHERE m.saved_search_id = in_saved_search))))
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION listed_posts(UUID, BOOLEAN, UUID, UUID);
+-- +goose StatementBegin
+CREATE FUNCTION listed_posts(for_user UUID, only_unread BOOLEAN, in_feed UUID) RETURNS SETOF posts
+    LANGUAGE sql STABLE AS
+$$
+SELECT p.*
+FROM posts p
+WHERE (p.feed_id IN (SELECT ff.feed_id FROM feed_follows ff WHERE ff.user_id = for_user)
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps INNER JOIN feed_follows ff ON ps.feed_id = ff.feed_id WHERE ff.user_id = for_user))
+  AND (NOT only_unread
+    OR NOT EXISTS(SELECT 1 FROM post_reads pr WHERE pr.post_id = p.id AND pr.user_id = for_user))
+  AND (in_feed IS NULL
+    OR p.feed_id = in_feed
+    OR p.id IN (SELECT ps.post_id FROM post_sources ps WHERE ps.feed_id = in_feed))
+  AND (in_feed IS NOT NULL OR p.canonical_url = ''
+    OR NOT EXISTS(SELECT 1
+                  FROM posts d INNER JOIN feed_follows dff ON d.feed_id = dff.feed_id
+                  WHERE dff.user_id = for_user
+                    AND d.canonical_url = p.canonical_url AND d.feed_id <> p.feed_id
+                    AND (d.created_at, d.id) < (p.created_at, p.id)))
+$$;
+-- +goose StatementEnd
+DROP TABLE saved_search_matches;
+DROP TABLE saved_searches;
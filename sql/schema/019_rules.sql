@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN author TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+-- Per-user rules that hide, mark read or highlight matching posts wherever posts are listed
+CREATE TABLE rules
+(
+    id         UUID      PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id    UUID      NOT NULL,
+    kind       TEXT      NOT NULL,
+    pattern    TEXT      NOT NULL,
+    action     TEXT      NOT NULL,
+    feed_id    UUID      NULL,
+    handle     BIGSERIAL UNIQUE,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (feed_id)
+    REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE rules;
+ALTER TABLE posts DROP COLUMN categories;
+ALTER TABLE posts DROP COLUMN author;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Digests the agg loop mails to a user, daily or weekly at a local time of day
+CREATE TABLE digest_schedules
+(
+    id           UUID      PRIMARY KEY,
+    created_at   TIMESTAMP NOT NULL,
+    updated_at   TIMESTAMP NOT NULL,
+    user_id      UUID      NOT NULL,
+    email        TEXT      NOT NULL,
+    frequency    TEXT      NOT NULL,
+    send_at      TEXT      NOT NULL,
+    weekday      INTEGER   NOT NULL DEFAULT 0,
+    group_by     TEXT      NOT NULL DEFAULT 'feed',
+    mark_read    BOOL      NOT NULL DEFAULT false,
+    last_sent_at TIMESTAMP NULL,
+    next_run_at  TIMESTAMP NOT NULL,
+    handle       BIGSERIAL UNIQUE,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX digest_schedules_next_run_at_idx ON digest_schedules (next_run_at);
+
+-- +goose Down
+DROP TABLE digest_schedules;